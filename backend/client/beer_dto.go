@@ -7,6 +7,7 @@ type BeerResponse struct {
 	FirstBrewed   string      `json:"first_brewed"` // "YYYY-MM"
 	Description   string      `json:"description"`
 	ABV           float64     `json:"abv"`
+	IBU           float64     `json:"ibu"`
 	Ingredients   Ingredients `json:"ingredients"`
 	FoodPairing   []string    `json:"food_pairing"`
 	BrewersTips   string      `json:"brewers_tips"`
@@ -14,13 +15,16 @@ type BeerResponse struct {
 }
 
 type BeerRequest struct {
-	BeerName     string `json:"beerName,omitempty"`
-	Yeast        string `json:"yeast,omitempty"`
-	BrewedBefore string `json:"brewedBefore,omitempty"` // format yyyy-mm
-	BrewedAfter  string `json:"brewedAfter,omitempty"`  // format yyyy-mm
-	Hops         string `json:"hops,omitempty"`
-	Malt         string `json:"malt,omitempty"`
-	Food         string `json:"food,omitempty"`
+	BeerName     string  `json:"beerName,omitempty" validate:"max=200"`
+	Yeast        string  `json:"yeast,omitempty" validate:"max=200"`
+	BrewedBefore string  `json:"brewedBefore,omitempty" validate:"omitempty,datetime=2006-01"` // format yyyy-mm
+	BrewedAfter  string  `json:"brewedAfter,omitempty" validate:"omitempty,datetime=2006-01"`  // format yyyy-mm
+	Hops         string  `json:"hops,omitempty" validate:"max=200"`
+	HopAttribute string  `json:"hopAttribute,omitempty" validate:"omitempty,oneof=bitter flavour flavor aroma"`
+	MinHops      float64 `json:"minHops,omitempty" validate:"gte=0"` // grams
+	Malt         string  `json:"malt,omitempty" validate:"max=200"`
+	MinMalt      float64 `json:"minMalt,omitempty" validate:"gte=0"` // grams
+	Food         string  `json:"food,omitempty" validate:"max=200"`
 }
 
 type Ingredients struct {
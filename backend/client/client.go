@@ -12,6 +12,16 @@ type Client interface {
 	ListBeers() ([]BeerResponse, error)
 }
 
+// FilteringClient is a Client whose upstream can narrow the list with a
+// BeerRequest. The result must keep every beer the service itself would
+// match, so criteria the upstream cannot match as loosely (substrings,
+// food synonyms, word stems) are ignored; beers that do not match may be
+// returned and are filtered out by the caller.
+type FilteringClient interface {
+	Client
+	FilterBeers(req BeerRequest) ([]BeerResponse, error)
+}
+
 type FakeBeerClient struct {
 	count int
 }
@@ -39,6 +49,7 @@ func (c *FakeBeerClient) fakeBeer(id int) BeerResponse {
 	firstBrewed := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Format("2006-01")
 
 	abv := math.Round((1.0+rand.Float64()*14.0)*10) / 10
+	ibu := float64(rand.Intn(120-5+1) + 5)
 
 	foods := make([]string, rand.Intn(5)+1)
 	for i := range foods {
@@ -52,6 +63,7 @@ func (c *FakeBeerClient) fakeBeer(id int) BeerResponse {
 		FirstBrewed: firstBrewed,
 		Description: gofakeit.Sentence(12),
 		ABV:         abv,
+		IBU:         ibu,
 		Ingredients: Ingredients{
 			Malt: []Malt{
 				{Name: "Extra Pale", Amount: Amount{Value: 5, Unit: "kilograms"}},
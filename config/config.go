@@ -16,6 +16,21 @@ const (
 	CacheClearTicker  = time.Duration(time.Second * 60)
 	ApiRateLimitRate  = time.Duration(time.Second * 60)
 	ApiRateLimitBurst = 10
+	CacheNegativeTTL  = time.Duration(time.Second * 10)
+	CacheShards       = 16
+	CacheCompression  = "none"
+	CacheCompressMin  = 4096
+	CacheL1           = "memory"
+	CacheL2           = "none"
+	CacheDiskDir      = "./.cache"
+	CacheRedisAddr    = "localhost:6379"
+	CacheRedisTimeout = time.Duration(time.Second * 2)
+	CacheRedisPool    = 4
+	SimilarityHops    = 0.3
+	SimilarityMalts   = 0.15
+	SimilarityABV     = 0.2
+	SimilarityStyle   = 0.2
+	SimilarityFood    = 0.15
 )
 
 type Configuration struct {
@@ -31,8 +46,53 @@ type Configuration struct {
 	Cache struct {
 		TTL         time.Duration `yaml:"ttl"`
 		ClearTicker time.Duration `yaml:"clearticker"`
+		// EncodedResponses keeps the serialized JSON body of list responses
+		// in cache and serves it with ETag / Cache-Control headers.
+		EncodedResponses bool `yaml:"encodedresponses"`
+		// NegativeTTL is how long upstream errors and empty results are cached.
+		NegativeTTL time.Duration `yaml:"negativettl"`
+
+		// L1 is the local cache level, L2 the shared one checked on L1 misses.
+		L1 string `yaml:"l1" validate:"omitempty,oneof=memory sharded disk redis none"`
+		L2 string `yaml:"l2" validate:"omitempty,oneof=memory sharded disk redis none"`
+		// Compression of serialized values bigger than Threshold bytes.
+		Compression struct {
+			Algorithm string `yaml:"algorithm" validate:"omitempty,oneof=none gzip zstd"`
+			Threshold int    `yaml:"threshold"`
+		} `yaml:"compression"`
+
+		// Shards is the number of shards of the sharded backend.
+		Shards int `yaml:"shards"`
+
+		Disk struct {
+			Dir string `yaml:"dir"`
+		} `yaml:"disk"`
+
+		Redis struct {
+			Addr     string        `yaml:"addr"`
+			Password string        `yaml:"password"`
+			DB       int           `yaml:"db"`
+			Timeout  time.Duration `yaml:"timeout"`
+			// PoolSize caps the open connections to redis.
+			PoolSize int `yaml:"pool_size" validate:"gte=0"`
+		} `yaml:"redis"`
 	} `yaml:"cache"`
 
+	Food struct {
+		// Synonyms lists, per food, the terms it also matches in food pairings.
+		Synonyms map[string][]string `yaml:"synonyms"`
+	} `yaml:"food"`
+
+	// Similarity weighs the features compared by /beer/:id/similar. A zero
+	// weight ignores the feature; all zero means the defaults.
+	Similarity struct {
+		Hops  float64 `yaml:"hops" validate:"gte=0"`
+		Malts float64 `yaml:"malts" validate:"gte=0"`
+		ABV   float64 `yaml:"abv" validate:"gte=0"`
+		Style float64 `yaml:"style" validate:"gte=0"`
+		Food  float64 `yaml:"food" validate:"gte=0"`
+	} `yaml:"similarity"`
+
 	ApiRateLimit struct {
 		Rate  time.Duration `yaml:"rate"`
 		Burst int           `yaml:"burst"`
@@ -72,6 +132,40 @@ func (cfg *Configuration) setDefaults() {
 	if cfg.Cache.ClearTicker == 0 {
 		cfg.Cache.ClearTicker = CacheClearTicker
 	}
+	if cfg.Cache.NegativeTTL == 0 {
+		cfg.Cache.NegativeTTL = CacheNegativeTTL
+	}
+	if cfg.Cache.L1 == "" {
+		cfg.Cache.L1 = CacheL1
+	}
+	if cfg.Cache.L2 == "" {
+		cfg.Cache.L2 = CacheL2
+	}
+	if cfg.Cache.Compression.Algorithm == "" {
+		cfg.Cache.Compression.Algorithm = CacheCompression
+	}
+	if cfg.Cache.Compression.Threshold == 0 {
+		cfg.Cache.Compression.Threshold = CacheCompressMin
+	}
+	if cfg.Cache.Shards == 0 {
+		cfg.Cache.Shards = CacheShards
+	}
+	if cfg.Cache.Disk.Dir == "" {
+		cfg.Cache.Disk.Dir = CacheDiskDir
+	}
+	if cfg.Cache.Redis.Addr == "" {
+		cfg.Cache.Redis.Addr = CacheRedisAddr
+	}
+	if cfg.Cache.Redis.Timeout == 0 {
+		cfg.Cache.Redis.Timeout = CacheRedisTimeout
+	}
+	if cfg.Cache.Redis.PoolSize == 0 {
+		cfg.Cache.Redis.PoolSize = CacheRedisPool
+	}
+	s := &cfg.Similarity
+	if s.Hops == 0 && s.Malts == 0 && s.ABV == 0 && s.Style == 0 && s.Food == 0 {
+		s.Hops, s.Malts, s.ABV, s.Style, s.Food = SimilarityHops, SimilarityMalts, SimilarityABV, SimilarityStyle, SimilarityFood
+	}
 	if cfg.ApiRateLimit.Rate == 0 {
 		cfg.ApiRateLimit.Rate = ApiRateLimitRate
 	}
@@ -0,0 +1,221 @@
+package beer
+
+import (
+	"errors"
+	"fmt"
+	backendbeer "interview-go/backend/client"
+	"strconv"
+	"strings"
+)
+
+const MaxPageLimit = 500
+
+// BeerQuery is the structured input of Service.GetFilteredBeers.
+type BeerQuery struct {
+	Filters BeerFilter
+	Sort    []SortKey
+	Page    Page
+	// Fields restricts the rendered BeerResponse fields to dotted JSON
+	// paths like "ingredients.hops.name"; empty means all.
+	Fields []string
+	// Facets lists the facet counts to return with the page, see Facets.
+	Facets []string
+}
+
+type BeerFilter struct {
+	Name        string // substring of the beer name
+	Styles      []Style
+	Year        int
+	Foods       []string
+	FoodMatch   string // MatchAny (default) or MatchAll
+	ABV         Range[float64]
+	Brewed      Range[string] // "yyyy-mm" months
+	Ingredients IngredientFilter
+	// Expr is a filter expression ANDed with the other filters, see ParseFilter.
+	Expr FilterExpr
+}
+
+// FieldError describes one invalid query parameter.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// Position is the 1-based character of a filter expression error.
+	Position int `json:"position,omitempty"`
+}
+
+// ValidationError lists everything wrong with a query.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return ErrInvalidQuery.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// Details is rendered by the server error handler next to the message.
+func (e *ValidationError) Details() any {
+	return e.Errors
+}
+
+func (e *ValidationError) add(field, format string, args ...any) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *ValidationError) orNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate reports every invalid part of the query as a *ValidationError.
+func (q BeerQuery) Validate() error {
+	ve := &ValidationError{}
+	q.Filters.validate(ve)
+
+	validateSort(q.Sort, ve)
+
+	q.Page.validate(ve)
+
+	validateFields(q.Fields, ve)
+
+	validateFacets(q.Facets, ve)
+
+	return ve.orNil()
+}
+
+func (bf BeerFilter) validate(ve *ValidationError) {
+	validateStyles(bf.Styles, ve)
+	if bf.Year < 0 {
+		ve.add("year", "must not be negative")
+	}
+	validateFoods(bf.Foods, bf.FoodMatch, ve)
+	validateABVRange(bf.ABV, ve)
+	validateBrewedRange(bf.Brewed, ve)
+	bf.Ingredients.validate(ve)
+	bf.Expr.validate(ve)
+}
+
+// Request translates the filter into the upstream BeerRequest. Only the
+// criteria the upstream understands are set, so its result still has to
+// be filtered with the compiled query.
+func (bf BeerFilter) Request() backendbeer.BeerRequest {
+	req := backendbeer.BeerRequest{
+		BeerName:     bf.Name,
+		Yeast:        bf.Ingredients.Yeast,
+		Hops:         bf.Ingredients.Hops,
+		HopAttribute: normalizeHopAttribute(bf.Ingredients.HopAttribute),
+		MinHops:      bf.Ingredients.MinHops,
+		Malt:         bf.Ingredients.Malt,
+		MinMalt:      bf.Ingredients.MinMalt,
+	}
+	if bf.Brewed.Lower.Set && !bf.Brewed.Lower.Inclusive {
+		req.BrewedAfter = bf.Brewed.Lower.Value
+	}
+	if bf.Brewed.Upper.Set && !bf.Brewed.Upper.Inclusive {
+		req.BrewedBefore = bf.Brewed.Upper.Value
+	}
+	if len(bf.Foods) == 1 {
+		req.Food = bf.Foods[0]
+	}
+	return req
+}
+
+// Query compiles a validated query without food synonyms.
+func (q BeerQuery) Query() Query {
+	return Engine{}.Compile(q)
+}
+
+// Key identifies the page returned for the query, projection aside, in caches.
+func (q BeerQuery) Key() string {
+	return q.resultKey() + "|" + q.Page.key() + "|facets=" + strings.Join(q.Facets, ",")
+}
+
+// resultKey identifies the filtered and sorted result before paging.
+func (q BeerQuery) resultKey() string {
+	return fmt.Sprintf("%s|sort=%s", q.Filters.String(), FormatSort(q.Sort))
+}
+
+func (bf BeerFilter) String() string {
+	foods := make([]string, 0, len(bf.Foods))
+	for _, f := range bf.Foods {
+		foods = append(foods, normalizeFood(f))
+	}
+	return fmt.Sprintf("name=%s|styles=%s|year=%d|foods=%s|match=%s|abv=%s|brewed=%s|%s|q=%s",
+		strings.ToLower(bf.Name), joinStyles(bf.Styles), bf.Year, strings.Join(foods, ","), bf.FoodMatch, bf.ABV, bf.Brewed, bf.Ingredients, bf.Expr.key())
+}
+
+// Params renders the filters and sort of the query as getFiltered query
+// parameters.
+func (q BeerQuery) Params() map[string]string {
+	params := q.Filters.Params()
+	if len(q.Sort) > 0 {
+		params["sort"] = FormatSort(q.Sort)
+	}
+	return params
+}
+
+// Params renders the effective filters as the getFiltered query
+// parameters that select them, e.g. {"style": "ipa", "abv_gte": "5"}.
+func (bf BeerFilter) Params() map[string]string {
+	params := map[string]string{}
+	set := func(name, value string) {
+		if value != "" {
+			params[name] = value
+		}
+	}
+	bound := func(b Bound[string], exclusive, inclusive string) {
+		if !b.Set {
+			return
+		}
+		if b.Inclusive {
+			set(inclusive, b.Value)
+		} else {
+			set(exclusive, b.Value)
+		}
+	}
+	number := func(v float64) string {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	abv := func(b Bound[float64]) Bound[string] {
+		return Bound[string]{Value: number(b.Value), Inclusive: b.Inclusive, Set: b.Set}
+	}
+
+	set("name", bf.Name)
+	set("style", joinStyles(bf.Styles))
+	if bf.Year != 0 {
+		set("year", strconv.Itoa(bf.Year))
+	}
+	set("hasFood", strings.Join(bf.Foods, ","))
+	if len(bf.Foods) > 0 {
+		set("match", bf.FoodMatch)
+	}
+	bound(abv(bf.ABV.Lower), "abv_gt", "abv_gte")
+	bound(abv(bf.ABV.Upper), "abv_lt", "abv_lte")
+	bound(bf.Brewed.Lower, "brewed_after", "brewed_from")
+	bound(bf.Brewed.Upper, "brewed_before", "brewed_until")
+
+	in := bf.Ingredients
+	set("hops", in.Hops)
+	set("hop_attribute", in.HopAttribute)
+	set("malt", in.Malt)
+	set("yeast", in.Yeast)
+	if in.MinHops > 0 {
+		set("hops_min", number(in.MinHops)+"g")
+	}
+	if in.MinMalt > 0 {
+		set("malt_min", number(in.MinMalt)+"g")
+	}
+	set("q", bf.Expr.String())
+	return params
+}
@@ -0,0 +1,185 @@
+package beer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/cache"
+	"interview-go/internal/search"
+	"log"
+	"time"
+)
+
+const catalogKey = "catalog"
+
+// catalogData is the cached form of one upstream beer list.
+// Version is a content hash, so equal lists always share it.
+type catalogData struct {
+	Version  string
+	LoadedAt time.Time
+	Beers    []backendbeer.BeerResponse
+}
+
+// catalog is an immutable snapshot of catalogData with its indexes.
+// It is rebuilt only when the cached version changes.
+type catalog struct {
+	catalogData
+
+	byID     map[int]int // beer ID -> position in Beers
+	text     *search.Index
+	features []features // parallel to Beers, for similarity ranking
+	foods    foodIndex
+	pairings map[int]foodPairings // beer ID -> tokenized food pairings
+}
+
+func newCatalogData(beers []backendbeer.BeerResponse) catalogData {
+	raw, err := json.Marshal(beers)
+	if err != nil {
+		log.Println(err)
+	}
+	sum := sha256.Sum256(raw)
+
+	return catalogData{
+		Version:  hex.EncodeToString(sum[:8]),
+		LoadedAt: time.Now(),
+		Beers:    beers,
+	}
+}
+
+func newCatalog(data catalogData) *catalog {
+	c := &catalog{
+		catalogData: data,
+		byID:        make(map[int]int, len(data.Beers)),
+		features:    make([]features, len(data.Beers)),
+		pairings:    make(map[int]foodPairings, len(data.Beers)),
+	}
+	docs := make(map[int][]search.Field, len(data.Beers))
+	for i, b := range data.Beers {
+		c.byID[b.ID] = i
+		c.features[i] = newFeatures(b)
+		c.pairings[b.ID] = newFoodPairings(b)
+		docs[b.ID] = []search.Field{
+			{Text: b.Name, Weight: 3},
+			{Text: b.Tagline, Weight: 2},
+			{Text: b.Description, Weight: 1},
+			{Text: b.BrewersTips, Weight: 1},
+		}
+	}
+	c.text = search.NewIndex(docs)
+	c.foods = newFoodIndex(data.Beers)
+
+	return c
+}
+
+func (c *catalog) beer(id int) (backendbeer.BeerResponse, bool) {
+	i, ok := c.byID[id]
+	if !ok {
+		return backendbeer.BeerResponse{}, false
+	}
+	return c.Beers[i], true
+}
+
+// CacheStatus tells where the catalog snapshot of a response came from.
+type CacheStatus string
+
+const (
+	CacheHit   CacheStatus = "hit"   // the cached catalog
+	CacheMiss  CacheStatus = "miss"  // loaded from the upstream
+	CacheStale CacheStatus = "stale" // the previous snapshot, the upstream being rate limited
+)
+
+// SnapshotInfo describes the catalog snapshot a result was computed from.
+type SnapshotInfo struct {
+	Version  string
+	LoadedAt time.Time
+	Cache    CacheStatus
+}
+
+func (c *catalog) info(status CacheStatus) SnapshotInfo {
+	return SnapshotInfo{Version: c.Version, LoadedAt: c.LoadedAt, Cache: status}
+}
+
+// Snapshot reports the catalog snapshot requests are served from.
+func (s *service) Snapshot() (SnapshotInfo, error) {
+	cat, status, err := s.load()
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	return cat.info(status), nil
+}
+
+// snapshot returns the current catalog, loading it from cache or from the
+// rate limited upstream when needed.
+func (s *service) snapshot() (*catalog, error) {
+	cat, _, err := s.load()
+	return cat, err
+}
+
+// load is snapshot reporting where the catalog came from. When the
+// upstream is rate limited the previous snapshot is served as stale.
+func (s *service) load() (*catalog, CacheStatus, error) {
+	cachedValue, err := s.cache.Get(catalogKey)
+	if err != nil && err != cache.ErrCacheMiss && err != cache.ErrTTLExpired {
+		return nil, "", err
+	}
+
+	switch v := cachedValue.(type) {
+	case nil:
+	case catalogData:
+		return s.adopt(v), CacheHit, nil
+	case negativeEntry:
+		if time.Now().Before(v.ExpiresAt) {
+			beers, err := v.replay()
+			if err != nil {
+				return nil, "", err
+			}
+			return newCatalog(newCatalogData(beers)), CacheHit, nil
+		}
+	default:
+		log.Println("malformed catalog data type in cache")
+	}
+
+	// simulating api rate limit
+	if !s.rateLimiter.Allow() {
+		if prev := s.previous(); prev != nil {
+			return prev, CacheStale, nil
+		}
+		return nil, "", ErrRateLimitExceeded
+	}
+
+	beers, err := s.client.ListBeers()
+	if err != nil {
+		s.setNegative(catalogKey, err)
+		return nil, "", err
+	}
+	if len(beers) == 0 {
+		s.setNegative(catalogKey, nil)
+		return newCatalog(newCatalogData(beers)), CacheMiss, nil
+	}
+
+	data := newCatalogData(beers)
+	if err := s.cache.Set(catalogKey, data); err != nil {
+		// do not return here, just log it
+		log.Println(err)
+	}
+
+	return s.adopt(data), CacheMiss, nil
+}
+
+func (s *service) previous() *catalog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// adopt keeps the built snapshot while the cached version is unchanged.
+func (s *service) adopt(data catalogData) *catalog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil || s.current.Version != data.Version {
+		s.current = newCatalog(data)
+	}
+	return s.current
+}
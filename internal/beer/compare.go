@@ -0,0 +1,197 @@
+package beer
+
+import (
+	backendbeer "interview-go/backend/client"
+	"strings"
+)
+
+// MaxCompareBeers bounds the beers of one comparison.
+const MaxCompareBeers = 10
+
+// BeerComparison lays beers side by side with the overlap of their
+// ingredients and food pairings.
+type BeerComparison struct {
+	Beers       []ComparedBeer `json:"beers"`
+	Hops        Overlap        `json:"hops"`
+	Malts       Overlap        `json:"malts"`
+	Yeast       Overlap        `json:"yeast"`
+	FoodPairing Overlap        `json:"food_pairing"`
+}
+
+// ComparedBeer is one column of a comparison.
+type ComparedBeer struct {
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Style       Style              `json:"style"`
+	ABV         float64            `json:"abv"`
+	IBU         float64            `json:"ibu"`
+	FirstBrewed string             `json:"first_brewed"`
+	Hops        []IngredientAmount `json:"hops"`
+	Malts       []IngredientAmount `json:"malts"`
+	Yeast       string             `json:"yeast"`
+	FoodPairing []string           `json:"food_pairing"`
+}
+
+// IngredientAmount is the total amount of one ingredient in a beer;
+// Grams is zero when the unit is unknown.
+type IngredientAmount struct {
+	Name  string  `json:"name"`
+	Grams float64 `json:"grams,omitempty"`
+}
+
+// Overlap splits values into those every compared beer has and those
+// only one of them has. Values shared by some but not all beers are in
+// neither. Values compare case-insensitively.
+type Overlap struct {
+	Shared []string         `json:"shared"`
+	Unique map[int][]string `json:"unique"` // beer ID -> values
+}
+
+func newComparison(beers []backendbeer.BeerResponse) BeerComparison {
+	out := BeerComparison{Beers: make([]ComparedBeer, 0, len(beers))}
+
+	hops := make([][]string, len(beers))
+	malts := make([][]string, len(beers))
+	yeast := make([][]string, len(beers))
+	foods := make([][]string, len(beers))
+	for i, b := range beers {
+		cb := ComparedBeer{
+			ID:          b.ID,
+			Name:        b.Name,
+			Style:       Classify(b),
+			ABV:         b.ABV,
+			IBU:         b.IBU,
+			FirstBrewed: b.FirstBrewed,
+			Yeast:       b.Ingredients.Yeast,
+			FoodPairing: b.FoodPairing,
+		}
+		for _, h := range b.Ingredients.Hops {
+			cb.Hops = addAmount(cb.Hops, h.Name, h.Amount)
+		}
+		for _, m := range b.Ingredients.Malt {
+			cb.Malts = addAmount(cb.Malts, m.Name, m.Amount)
+		}
+		out.Beers = append(out.Beers, cb)
+
+		hops[i] = ingredientNames(cb.Hops)
+		malts[i] = ingredientNames(cb.Malts)
+		if b.Ingredients.Yeast != "" {
+			yeast[i] = []string{b.Ingredients.Yeast}
+		}
+		foods[i] = b.FoodPairing
+	}
+
+	out.Hops = overlap(beers, hops, foldName)
+	out.Malts = overlap(beers, malts, foldName)
+	out.Yeast = overlap(beers, yeast, foldName)
+	out.FoodPairing = overlap(beers, foods, normalizeFood)
+	return out
+}
+
+func foldName(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// addAmount adds an ingredient to the list, summing additions of the same
+// ingredient, like a hop added at the start and at the end of the boil.
+func addAmount(list []IngredientAmount, name string, amount backendbeer.Amount) []IngredientAmount {
+	grams, _ := Grams(amount)
+	for i := range list {
+		if foldName(list[i].Name) == foldName(name) {
+			list[i].Grams = round2(list[i].Grams + grams)
+			return list
+		}
+	}
+	return append(list, IngredientAmount{Name: strings.TrimSpace(name), Grams: round2(grams)})
+}
+
+func ingredientNames(list []IngredientAmount) []string {
+	out := make([]string, len(list))
+	for i, ia := range list {
+		out[i] = ia.Name
+	}
+	return out
+}
+
+// overlap compares values[i], the values of beers[i], by their key.
+// Shared keeps the order and spelling of the first beer.
+func overlap(beers []backendbeer.BeerResponse, values [][]string, key func(string) string) Overlap {
+	// key -> how many beers have it
+	owners := map[string]int{}
+	for _, vs := range values {
+		seen := map[string]bool{}
+		for _, v := range vs {
+			if k := key(v); k != "" && !seen[k] {
+				seen[k] = true
+				owners[k]++
+			}
+		}
+	}
+
+	o := Overlap{Shared: []string{}, Unique: make(map[int][]string, len(beers))}
+	for i, vs := range values {
+		unique := []string{}
+		seen := map[string]bool{}
+		for _, v := range vs {
+			k := key(v)
+			if k == "" || seen[k] {
+				continue
+			}
+			seen[k] = true
+			switch owners[k] {
+			case len(beers):
+				if i == 0 {
+					o.Shared = append(o.Shared, strings.TrimSpace(v))
+				}
+			case 1:
+				unique = append(unique, strings.TrimSpace(v))
+			}
+		}
+		o.Unique[beers[i].ID] = unique
+	}
+	return o
+}
+
+// distinctIDs drops repeated IDs, keeping the first occurrence, and
+// checks that between 2 and MaxCompareBeers beers remain.
+func distinctIDs(ids []int) ([]int, error) {
+	distinct := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	if len(distinct) < 2 || len(distinct) > MaxCompareBeers {
+		ve := &ValidationError{}
+		ve.add("ids", "must name between 2 and %d distinct beers", MaxCompareBeers)
+		return nil, ve
+	}
+	return distinct, nil
+}
+
+// CompareBeers compares the beers with ids in the given order. Repeated
+// IDs are compared once; at least two distinct beers are required and an
+// unknown ID is ErrBeerNotFound.
+func (s *service) CompareBeers(ids []int) (BeerComparison, error) {
+	distinct, err := distinctIDs(ids)
+	if err != nil {
+		return BeerComparison{}, err
+	}
+
+	cat, err := s.snapshot()
+	if err != nil {
+		return BeerComparison{}, err
+	}
+
+	beers := make([]backendbeer.BeerResponse, 0, len(distinct))
+	for _, id := range distinct {
+		b, ok := cat.beer(id)
+		if !ok {
+			return BeerComparison{}, ErrBeerNotFound
+		}
+		beers = append(beers, b)
+	}
+	return newComparison(beers), nil
+}
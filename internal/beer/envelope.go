@@ -0,0 +1,117 @@
+package beer
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MediaTypeEnvelopeV1 opts a list endpoint into Envelope responses, as
+// does the envelope=v1 query parameter. Without either, lists keep their
+// bare array bodies.
+const MediaTypeEnvelopeV1 = "application/vnd.beer.v1+json"
+
+// Envelope wraps a page of a list endpoint with metadata and links.
+type Envelope struct {
+	Data  any           `json:"data"`
+	Meta  EnvelopeMeta  `json:"meta"`
+	Links EnvelopeLinks `json:"links"`
+}
+
+// EnvelopeMeta describes how a page was computed.
+type EnvelopeMeta struct {
+	// Filters are the effective filters, defaults included, as query
+	// parameters.
+	Filters  map[string]string `json:"filters"`
+	Total    int               `json:"total"`
+	Count    int               `json:"count"`
+	Offset   int               `json:"offset"`
+	Limit    int               `json:"limit,omitempty"`
+	Cache    CacheStatus       `json:"cache"`
+	Snapshot SnapshotMeta      `json:"snapshot"`
+	Facets   Facets            `json:"facets,omitempty"`
+}
+
+// SnapshotMeta identifies the catalog snapshot of a page and its age.
+type SnapshotMeta struct {
+	Version    string    `json:"version"`
+	LoadedAt   time.Time `json:"loaded_at"`
+	AgeSeconds float64   `json:"age_seconds"`
+}
+
+// EnvelopeLinks are absolute URLs; next and prev are empty at either end.
+type EnvelopeLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// wantsEnvelope reports whether the client opted into Envelope responses.
+func wantsEnvelope(c echo.Context) bool {
+	return c.QueryParam("envelope") == "v1" ||
+		strings.Contains(c.Request().Header.Get(echo.HeaderAccept), MediaTypeEnvelopeV1)
+}
+
+// varyOnAccept marks a response whose body depends on the Accept header,
+// so shared and browser caches keep envelopes and bare bodies apart.
+func varyOnAccept(c echo.Context) {
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+}
+
+func (p BeerPage) meta(filters map[string]string) EnvelopeMeta {
+	return newEnvelopeMeta(filters, p.Total, len(p.Beers), p.Offset, p.Limit, p.Facets, p.Snapshot)
+}
+
+func (p SearchPage) meta(filters map[string]string) EnvelopeMeta {
+	return newEnvelopeMeta(filters, p.Total, len(p.Hits), p.Offset, p.Limit, p.Facets, p.Snapshot)
+}
+
+func newEnvelopeMeta(filters map[string]string, total, count, offset, limit int, facets Facets, snap SnapshotInfo) EnvelopeMeta {
+	if filters == nil {
+		filters = map[string]string{}
+	}
+	return EnvelopeMeta{
+		Filters: filters,
+		Total:   total,
+		Count:   count,
+		Offset:  offset,
+		Limit:   limit,
+		Cache:   snap.Cache,
+		Snapshot: SnapshotMeta{
+			Version:    snap.Version,
+			LoadedAt:   snap.LoadedAt,
+			AgeSeconds: round2(time.Since(snap.LoadedAt).Seconds()),
+		},
+		Facets: facets,
+	}
+}
+
+// renderEnvelope writes data with its metadata. Envelopes are never
+// served from the response cache, since the cache status and the snapshot
+// age change from one request to the next. An empty page is a 200 with
+// empty data rather than a 204.
+func renderEnvelope(c echo.Context, data any, meta EnvelopeMeta, next, prev string) error {
+	setPageHeaders(c, meta.Total, next, prev)
+	if meta.Count == 0 {
+		data = []any{}
+	}
+
+	env := Envelope{Data: data, Meta: meta, Links: EnvelopeLinks{Self: requestURL(c)}}
+	if next != "" {
+		env.Links.Next = pageURL(c, next)
+	}
+	if prev != "" {
+		env.Links.Prev = pageURL(c, prev)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, MediaTypeEnvelopeV1)
+	return c.JSON(http.StatusOK, env)
+}
+
+func requestURL(c echo.Context) string {
+	req := c.Request()
+	return fmt.Sprintf("%s://%s%s", c.Scheme(), req.Host, req.URL.RequestURI())
+}
@@ -0,0 +1,532 @@
+package beer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// Response formats of the list endpoints, selected with format= or the
+// Accept header.
+const (
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXML    = "xml"
+	FormatYAML   = "yaml"
+)
+
+// formatMediaTypes is the Content-Type written for each format.
+var formatMediaTypes = map[string]string{
+	FormatJSON:   echo.MIMEApplicationJSON,
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXML:    "application/xml; charset=utf-8",
+	FormatYAML:   "application/yaml",
+}
+
+// acceptedMediaTypes maps the media ranges of an Accept header to formats.
+// Browsers ask for text/html before application/xml, so they keep getting
+// JSON.
+var acceptedMediaTypes = map[string]string{
+	"*/*":                  FormatJSON,
+	"text/html":            FormatJSON,
+	"application/*":        FormatJSON,
+	"application/json":     FormatJSON,
+	MediaTypeEnvelopeV1:    FormatJSON,
+	"text/*":               FormatCSV,
+	"text/csv":             FormatCSV,
+	"application/x-ndjson": FormatNDJSON,
+	"application/ndjson":   FormatNDJSON,
+	"application/xml":      FormatXML,
+	"text/xml":             FormatXML,
+	"application/yaml":     FormatYAML,
+	"application/x-yaml":   FormatYAML,
+	"text/yaml":            FormatYAML,
+}
+
+// exportFlushEvery is the number of items written between flushes of a
+// streamed export.
+const exportFlushEvery = 100
+
+// negotiateFormat picks the format of a list response. format= takes
+// precedence over Accept; within Accept the highest q wins and ties go
+// to the first range. A format gets the q of the most specific range that
+// matches it, so text/csv;q=0 refuses CSV even next to text/*. Nothing
+// acceptable is a 406. Every response negotiated here, envelopes included,
+// varies with Accept.
+func negotiateFormat(c echo.Context) (string, error) {
+	varyOnAccept(c)
+
+	if f := strings.ToLower(strings.TrimSpace(c.QueryParam("format"))); f != "" {
+		if _, ok := formatMediaTypes[f]; !ok {
+			return "", echo.NewHTTPError(http.StatusNotAcceptable,
+				fmt.Sprintf("format must be one of %s, got %q", strings.Join(formatNames(), ", "), f))
+		}
+		return f, nil
+	}
+
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	if strings.TrimSpace(accept) == "" {
+		return FormatJSON, nil
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, r := range ranges {
+		f, ok := acceptedMediaTypes[r.mediaType]
+		if !ok {
+			continue
+		}
+		// a wildcard proposes the main type of its format, which a more
+		// specific range may still refuse
+		mediaType := r.mediaType
+		if strings.HasSuffix(mediaType, "/*") {
+			mediaType, _, _ = strings.Cut(formatMediaTypes[f], ";")
+		}
+		if q := acceptQuality(ranges, mediaType); q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	if best == "" {
+		return "", echo.NewHTTPError(http.StatusNotAcceptable,
+			fmt.Sprintf("cannot produce %q, supported formats are %s", accept, strings.Join(formatNames(), ", ")))
+	}
+	return best, nil
+}
+
+// acceptRange is one media range of an Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality is the q of the most specific range matching mediaType:
+// type/subtype before type/* before */*. It is 0 when none matches.
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch r.mediaType {
+		case mediaType:
+			s = 2
+		case mainType + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+func formatNames() []string {
+	names := make([]string, 0, len(formatMediaTypes))
+	for name := range formatMediaTypes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// exportWriter writes the items of one export in a format; flush pushes
+// what it buffered to the response.
+type exportWriter interface {
+	begin() error
+	item(raw json.RawMessage) error
+	flush() error
+	end() error
+}
+
+// streamExport writes items in format, projected on fields, one item at a
+// time so large results are never buffered whole. The status is sent
+// before the first item, so a failure after that cannot become an error
+// response: it is logged and the connection is aborted, leaving the client
+// with a truncated body instead of a complete-looking one.
+func streamExport[T any](c echo.Context, format string, items []T, fields []string) error {
+	tree := parseFields(fields)
+	var zero T
+	w := c.Response()
+
+	var ew exportWriter
+	switch format {
+	case FormatCSV:
+		ew = &csvExport{w: csv.NewWriter(w), columns: exportColumns(reflect.TypeOf(zero), tree)}
+	case FormatNDJSON:
+		ew = &ndjsonExport{w: w}
+	case FormatXML:
+		ew = &xmlExport{w: w, enc: xml.NewEncoder(w)}
+	case FormatYAML:
+		ew = &yamlExport{w: w}
+	default:
+		return echo.NewHTTPError(http.StatusNotAcceptable, "unsupported format "+format)
+	}
+
+	w.Header().Set(echo.HeaderContentType, formatMediaTypes[format])
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeExport(w, ew, items, tree); err != nil {
+		log.Printf("%s export aborted: %v", format, err)
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+func writeExport[T any](w *echo.Response, ew exportWriter, items []T, tree fieldTree) error {
+	if err := ew.begin(); err != nil {
+		return err
+	}
+	for i, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if raw, err = projectRaw(raw, tree); err != nil {
+			return err
+		}
+		if err := ew.item(raw); err != nil {
+			return err
+		}
+		if (i+1)%exportFlushEvery == 0 {
+			if err := ew.flush(); err != nil {
+				return err
+			}
+			w.Flush()
+		}
+	}
+	return ew.end()
+}
+
+// jsonValue is a decoded JSON value that keeps the order of object keys,
+// so every format lists fields like the JSON response does.
+type jsonValue struct {
+	scalar any // string, json.Number, bool or nil
+	keys   []string
+	fields map[string]*jsonValue // set for objects
+	items  []*jsonValue          // set for arrays
+	array  bool
+}
+
+func decodeJSONValue(raw []byte) (*jsonValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return readJSONValue(dec)
+}
+
+func readJSONValue(dec *json.Decoder) (*jsonValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		v := &jsonValue{fields: map[string]*jsonValue{}}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			child, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			v.keys = append(v.keys, key)
+			v.fields[key] = child
+		}
+		_, err := dec.Token()
+		return v, err
+	case json.Delim('['):
+		v := &jsonValue{array: true, items: []*jsonValue{}}
+		for dec.More() {
+			child, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			v.items = append(v.items, child)
+		}
+		_, err := dec.Token()
+		return v, err
+	default:
+		return &jsonValue{scalar: tok}, nil
+	}
+}
+
+func (v *jsonValue) text() string {
+	switch s := v.scalar.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	default:
+		return fmt.Sprint(s)
+	}
+}
+
+// ndjsonExport writes one JSON document per line.
+type ndjsonExport struct {
+	w io.Writer
+}
+
+func (e *ndjsonExport) begin() error { return nil }
+func (e *ndjsonExport) flush() error { return nil }
+func (e *ndjsonExport) end() error   { return nil }
+
+func (e *ndjsonExport) item(raw json.RawMessage) error {
+	_, err := e.w.Write(append(raw, '\n'))
+	return err
+}
+
+// csvExport writes one row per item. Nested objects are flattened into
+// dotted columns like ingredients.hops.name; the values of arrays are
+// joined with "; " in one cell.
+type csvExport struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (e *csvExport) begin() error { return e.w.Write(e.columns) }
+
+func (e *csvExport) item(raw json.RawMessage) error {
+	v, err := decodeJSONValue(raw)
+	if err != nil {
+		return err
+	}
+	cells := map[string][]string{}
+	flattenJSON(v, "", cells)
+
+	row := make([]string, len(e.columns))
+	for i, col := range e.columns {
+		row[i] = strings.Join(cells[col], "; ")
+	}
+	return e.w.Write(row)
+}
+
+func (e *csvExport) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExport) end() error { return e.flush() }
+
+func flattenJSON(v *jsonValue, path string, cells map[string][]string) {
+	switch {
+	case v.fields != nil:
+		for _, k := range v.keys {
+			flattenJSON(v.fields[k], joinPath(path, k), cells)
+		}
+	case v.array:
+		for _, item := range v.items {
+			flattenJSON(item, path, cells)
+		}
+	default:
+		cells[path] = append(cells[path], v.text())
+	}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// exportColumns lists the leaf JSON paths of t in declaration order,
+// restricted to the projection tree when it is not empty.
+func exportColumns(t reflect.Type, tree fieldTree) []string {
+	var cols []string
+	var walk func(t reflect.Type, prefix string, tree fieldTree)
+	walk = func(t reflect.Type, prefix string, tree fieldTree) {
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			cols = append(cols, prefix)
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.Anonymous {
+				// embedded structs are inlined by encoding/json
+				walk(sf.Type, prefix, tree)
+				continue
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			sub := fieldTree{}
+			if len(tree) > 0 {
+				var ok bool
+				if sub, ok = tree[name]; !ok {
+					continue
+				}
+			}
+			walk(sf.Type, joinPath(prefix, name), sub)
+		}
+	}
+	walk(t, "", tree)
+	return cols
+}
+
+// xmlExport writes <beers> with one <beer> element per item. Object keys
+// become elements and array values repeat their element.
+type xmlExport struct {
+	w   io.Writer
+	enc *xml.Encoder
+}
+
+func (e *xmlExport) begin() error {
+	if _, err := io.WriteString(e.w, xml.Header); err != nil {
+		return err
+	}
+	e.enc.Indent("", "  ")
+	return e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "beers"}})
+}
+
+func (e *xmlExport) item(raw json.RawMessage) error {
+	v, err := decodeJSONValue(raw)
+	if err != nil {
+		return err
+	}
+	return e.element("beer", v)
+}
+
+func (e *xmlExport) element(name string, v *jsonValue) error {
+	if v.array {
+		for _, item := range v.items {
+			if err := e.element(name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if err := e.enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if v.fields != nil {
+		for _, k := range v.keys {
+			if err := e.element(k, v.fields[k]); err != nil {
+				return err
+			}
+		}
+	} else if err := e.enc.EncodeToken(xml.CharData(v.text())); err != nil {
+		return err
+	}
+	return e.enc.EncodeToken(start.End())
+}
+
+func (e *xmlExport) flush() error { return e.enc.Flush() }
+
+func (e *xmlExport) end() error {
+	if err := e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "beers"}}); err != nil {
+		return err
+	}
+	return e.enc.Flush()
+}
+
+// yamlExport writes a YAML sequence, one entry per item. Each entry is
+// encoded on its own and entries concatenate into a single sequence.
+type yamlExport struct {
+	w     io.Writer
+	empty bool
+}
+
+func (e *yamlExport) begin() error {
+	e.empty = true
+	return nil
+}
+
+func (e *yamlExport) flush() error { return nil }
+
+func (e *yamlExport) item(raw json.RawMessage) error {
+	v, err := decodeJSONValue(raw)
+	if err != nil {
+		return err
+	}
+	e.empty = false
+	// a new encoder per entry, a shared one would separate them with "---"
+	enc := yaml.NewEncoder(e.w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{v.yamlNode()}}); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (e *yamlExport) end() error {
+	if e.empty {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+func (v *jsonValue) yamlNode() *yaml.Node {
+	switch {
+	case v.fields != nil:
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range v.keys {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, v.fields[k].yamlNode())
+		}
+		return n
+	case v.array:
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v.items {
+			n.Content = append(n.Content, item.yamlNode())
+		}
+		return n
+	}
+
+	switch s := v.scalar.(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(s)}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(s.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: s.String()}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.text()}
+	}
+}
@@ -0,0 +1,144 @@
+package beer
+
+import (
+	"cmp"
+	"fmt"
+	backendbeer "interview-go/backend/client"
+	"slices"
+	"strings"
+)
+
+// Facet names accepted by the facets parameter.
+const (
+	FacetFood      = "food"
+	FacetStyle     = "style"
+	FacetDecade    = "decade"
+	FacetABVBucket = "abv_bucket"
+)
+
+var facetNames = []string{FacetFood, FacetStyle, FacetDecade, FacetABVBucket}
+
+// MaxFacetValues bounds the food facet, the only one with open values.
+const MaxFacetValues = 20
+
+// abvBuckets are the lower bounds of the ABV facet buckets; each bucket
+// ends where the next one starts, like abv_gte=4&abv_lt=5.
+var abvBuckets = []float64{0, 4, 5, 6, 7, 8, 10}
+
+// Facets maps a facet name to the result count of each of its values.
+type Facets map[string][]ValueCount
+
+// facetCounter accumulates the requested facets one beer at a time, so
+// they are computed in the same pass that walks the result.
+type facetCounter struct {
+	names  []string
+	counts map[string]*counter
+}
+
+func newFacetCounter(names []string) *facetCounter {
+	fc := &facetCounter{names: names, counts: make(map[string]*counter, len(names))}
+	for _, n := range names {
+		fc.counts[n] = newCounter()
+	}
+	return fc
+}
+
+func (fc *facetCounter) add(b backendbeer.BeerResponse) {
+	if len(fc.names) == 0 {
+		return
+	}
+	seen := map[string]bool{}
+	for _, name := range fc.names {
+		c := fc.counts[name]
+		switch name {
+		case FacetFood:
+			for _, f := range b.FoodPairing {
+				c.addOnce(seen, name, f)
+			}
+		case FacetStyle:
+			c.addOnce(seen, name, string(Classify(b)))
+		case FacetDecade:
+			if year := extractYear(b.FirstBrewed); year > 0 {
+				c.addOnce(seen, name, fmt.Sprintf("%ds", year/10*10))
+			}
+		case FacetABVBucket:
+			c.addOnce(seen, name, abvBucket(b.ABV))
+		}
+	}
+}
+
+// result orders food and style by count and decades and ABV buckets by
+// their natural order.
+func (fc *facetCounter) result() Facets {
+	if len(fc.names) == 0 {
+		return nil
+	}
+	out := make(Facets, len(fc.names))
+	for _, name := range fc.names {
+		c := fc.counts[name]
+		switch name {
+		case FacetFood:
+			out[name] = c.top(MaxFacetValues)
+		case FacetStyle:
+			out[name] = c.top(len(c.counts))
+		case FacetDecade:
+			out[name] = c.ordered(func(a, b string) int { return cmp.Compare(a, b) })
+		case FacetABVBucket:
+			out[name] = c.ordered(func(a, b string) int {
+				return cmp.Compare(abvBucketIndex(a), abvBucketIndex(b))
+			})
+		}
+	}
+	return out
+}
+
+// ordered returns every value sorted with compare.
+func (c *counter) ordered(compare func(a, b string) int) []ValueCount {
+	out := c.top(len(c.counts))
+	slices.SortFunc(out, func(a, b ValueCount) int { return compare(a.Value, b.Value) })
+	return out
+}
+
+// abvBucket labels the bucket of abv, e.g. "4-5" or "10+".
+func abvBucket(abv float64) string {
+	i := len(abvBuckets) - 1
+	for i > 0 && abv < abvBuckets[i] {
+		i--
+	}
+	return abvBucketLabel(i)
+}
+
+func abvBucketLabel(i int) string {
+	if i == len(abvBuckets)-1 {
+		return fmt.Sprintf("%g+", abvBuckets[i])
+	}
+	return fmt.Sprintf("%g-%g", abvBuckets[i], abvBuckets[i+1])
+}
+
+func abvBucketIndex(label string) int {
+	for i := range abvBuckets {
+		if abvBucketLabel(i) == label {
+			return i
+		}
+	}
+	return len(abvBuckets)
+}
+
+// ParseFacets reads a comma separated list of facet names.
+func ParseFacets(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" && !slices.Contains(out, part) {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func validateFacets(names []string, ve *ValidationError) {
+	for _, n := range names {
+		if !slices.Contains(facetNames, n) {
+			ve.add("facets", "unknown facet %q, expected one of %s", n, strings.Join(facetNames, ","))
+		}
+	}
+}
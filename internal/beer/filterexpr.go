@@ -0,0 +1,338 @@
+package beer
+
+import (
+	"errors"
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/expr"
+	"slices"
+	"strings"
+	"time"
+)
+
+// exprField is a BeerResponse field usable in filter expressions.
+type exprField struct {
+	kind expr.LiteralKind
+	ops  []string
+	// check validates the literal beyond its kind, e.g. month formats.
+	check func(v expr.Literal) error
+	// compile builds the predicate for a positive operator: = ~ < <= > >=.
+	compile func(e Engine, op string, v expr.Literal) Predicate
+}
+
+var (
+	numberOps = []string{"=", "!=", "<", "<=", ">", ">="}
+	textOps   = []string{"=", "!=", "~", "!~"}
+	equalOps  = []string{"=", "!="}
+)
+
+// exprFields maps field names, including the dotted JSON paths of
+// ingredients, to their type and compiled predicate.
+var exprFields = map[string]exprField{
+	"id":             numberField(func(b backendbeer.BeerResponse) float64 { return float64(b.ID) }),
+	"ibu":            numberField(func(b backendbeer.BeerResponse) float64 { return b.IBU }),
+	"year":           numberField(func(b backendbeer.BeerResponse) float64 { return float64(extractYear(b.FirstBrewed)) }),
+	"name":           textField(func(b backendbeer.BeerResponse) []string { return []string{b.Name} }),
+	"tagline":        textField(func(b backendbeer.BeerResponse) []string { return []string{b.Tagline} }),
+	"description":    textField(func(b backendbeer.BeerResponse) []string { return []string{b.Description} }),
+	"brewers_tips":   textField(func(b backendbeer.BeerResponse) []string { return []string{b.BrewersTips} }),
+	"contributed_by": textField(func(b backendbeer.BeerResponse) []string { return []string{b.ContributedBy} }),
+	"abv": {
+		kind: expr.Number,
+		ops:  numberOps,
+		compile: func(_ Engine, op string, v expr.Literal) Predicate {
+			return ABVIn(rangeFor(op, v.Num))
+		},
+	},
+	"first_brewed": {
+		kind: expr.String,
+		ops:  numberOps,
+		check: func(v expr.Literal) error {
+			if _, err := time.Parse(monthLayout, v.Str); err != nil {
+				return errors.New("must be a month in yyyy-mm format")
+			}
+			return nil
+		},
+		compile: func(_ Engine, op string, v expr.Literal) Predicate {
+			return BrewedIn(rangeFor(op, v.Str))
+		},
+	},
+	"food_pairing": {
+		kind: expr.String,
+		ops:  textOps,
+		compile: func(e Engine, op string, v expr.Literal) Predicate {
+			if op == "~" {
+				return pairsWithFoods([]string{v.Str}, MatchAny, e.Synonyms, e.pairings)
+			}
+			return anyEqualFold(func(b backendbeer.BeerResponse) []string { return b.FoodPairing }, v.Str)
+		},
+	},
+	"style": {
+		kind: expr.String,
+		ops:  equalOps,
+		check: func(v expr.Literal) error {
+			if !knownStyle(Style(strings.ToLower(v.Str))) {
+				return errors.New("unknown style, expected one of " + joinStyles(Styles()))
+			}
+			return nil
+		},
+		compile: func(_ Engine, _ string, v expr.Literal) Predicate {
+			return HasStyle(Style(strings.ToLower(v.Str)))
+		},
+	},
+	"ingredients.hops.name": ingredientField(func(name string) Predicate { return UsesHop(IngredientFilter{Hops: name}) },
+		func(b backendbeer.BeerResponse) []string {
+			names := make([]string, len(b.Ingredients.Hops))
+			for i, h := range b.Ingredients.Hops {
+				names[i] = h.Name
+			}
+			return names
+		}),
+	"ingredients.malt.name": ingredientField(func(name string) Predicate { return UsesMalt(name, 0) },
+		func(b backendbeer.BeerResponse) []string {
+			names := make([]string, len(b.Ingredients.Malt))
+			for i, m := range b.Ingredients.Malt {
+				names[i] = m.Name
+			}
+			return names
+		}),
+	"ingredients.yeast": ingredientField(UsesYeast,
+		func(b backendbeer.BeerResponse) []string { return []string{b.Ingredients.Yeast} }),
+	"ingredients.hops.attribute": {
+		kind: expr.String,
+		ops:  equalOps,
+		check: func(v expr.Literal) error {
+			if _, ok := hopAttributes[strings.ToLower(v.Str)]; !ok {
+				return errors.New("must be bitter, flavour or aroma")
+			}
+			return nil
+		},
+		compile: func(_ Engine, _ string, v expr.Literal) Predicate {
+			return UsesHop(IngredientFilter{HopAttribute: v.Str})
+		},
+	},
+}
+
+// exprAliases are the short names of the query parameters.
+var exprAliases = map[string]string{
+	"brewed":        "first_brewed",
+	"food":          "food_pairing",
+	"hops":          "ingredients.hops.name",
+	"hop_attribute": "ingredients.hops.attribute",
+	"malt":          "ingredients.malt.name",
+	"yeast":         "ingredients.yeast",
+}
+
+func numberField(get func(backendbeer.BeerResponse) float64) exprField {
+	return exprField{
+		kind: expr.Number,
+		ops:  numberOps,
+		compile: func(_ Engine, op string, v expr.Literal) Predicate {
+			r := rangeFor(op, v.Num)
+			return func(b backendbeer.BeerResponse) bool {
+				return r.Contains(get(b))
+			}
+		},
+	}
+}
+
+// textField matches case-insensitively: = compares whole values and ~
+// looks for a substring.
+func textField(get func(backendbeer.BeerResponse) []string) exprField {
+	return exprField{
+		kind: expr.String,
+		ops:  textOps,
+		compile: func(_ Engine, op string, v expr.Literal) Predicate {
+			if op == "~" {
+				return func(b backendbeer.BeerResponse) bool {
+					for _, s := range get(b) {
+						if containsFold(s, v.Str) {
+							return true
+						}
+					}
+					return false
+				}
+			}
+			return anyEqualFold(get, v.Str)
+		},
+	}
+}
+
+// ingredientField uses the ingredient filter predicate for ~ so both
+// spellings of a filter behave the same.
+func ingredientField(contains func(string) Predicate, get func(backendbeer.BeerResponse) []string) exprField {
+	f := textField(get)
+	compileEqual := f.compile
+	f.compile = func(e Engine, op string, v expr.Literal) Predicate {
+		if op == "~" {
+			return contains(v.Str)
+		}
+		return compileEqual(e, op, v)
+	}
+	return f
+}
+
+func anyEqualFold(get func(backendbeer.BeerResponse) []string, want string) Predicate {
+	want = strings.TrimSpace(want)
+	return func(b backendbeer.BeerResponse) bool {
+		for _, s := range get(b) {
+			if strings.EqualFold(strings.TrimSpace(s), want) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// rangeFor turns a comparison into the Range used by the normal filters.
+func rangeFor[T string | float64](op string, v T) Range[T] {
+	switch op {
+	case "<":
+		return Range[T]{Upper: Bound[T]{Value: v, Set: true}}
+	case "<=":
+		return Range[T]{Upper: Bound[T]{Value: v, Inclusive: true, Set: true}}
+	case ">":
+		return Range[T]{Lower: Bound[T]{Value: v, Set: true}}
+	case ">=":
+		return Range[T]{Lower: Bound[T]{Value: v, Inclusive: true, Set: true}}
+	default:
+		b := Bound[T]{Value: v, Inclusive: true, Set: true}
+		return Range[T]{Lower: b, Upper: b}
+	}
+}
+
+// ParseFilter parses and type checks a filter expression such as
+// abv >= 5 and (food ~ "chicken" or food ~ "lamb") and not style = "stout".
+// Errors are *expr.Error values with the position of the offending token.
+func ParseFilter(src string) (expr.Node, error) {
+	n, err := expr.Parse(src, expr.Limits{})
+	if err != nil {
+		return nil, err
+	}
+	if err := typeCheck(src, n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func typeCheck(src string, n expr.Node) error {
+	switch n := n.(type) {
+	case *expr.Logical:
+		if err := typeCheck(src, n.Left); err != nil {
+			return err
+		}
+		return typeCheck(src, n.Right)
+	case *expr.Not:
+		return typeCheck(src, n.X)
+	case *expr.Comparison:
+		f, ok := lookupExprField(n.Field)
+		if !ok {
+			return expr.Errorf(src, n.FieldPos, "unknown field %q, expected one of %s", n.Field, exprFieldNames())
+		}
+		if !slices.Contains(f.ops, n.Op) {
+			return expr.Errorf(src, n.OpPos, "operator %q cannot be used with %s, expected one of %s",
+				n.Op, n.Field, strings.Join(f.ops, " "))
+		}
+		if n.Value.Kind != f.kind {
+			return expr.Errorf(src, n.Value.ValPos, "%s is a %s field, got %s %s", n.Field, f.kind, n.Value.Kind, n.Value)
+		}
+		if f.check != nil {
+			if err := f.check(n.Value); err != nil {
+				return expr.Errorf(src, n.Value.ValPos, "%s: %v, got %s", n.Field, err, n.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// compileExpr builds the predicate of a type checked expression.
+func (e Engine) compileExpr(n expr.Node) Predicate {
+	switch n := n.(type) {
+	case *expr.Logical:
+		if n.Op == "or" {
+			return Or(e.compileExpr(n.Left), e.compileExpr(n.Right))
+		}
+		return And(e.compileExpr(n.Left), e.compileExpr(n.Right))
+	case *expr.Not:
+		return Not(e.compileExpr(n.X))
+	case *expr.Comparison:
+		f, _ := lookupExprField(n.Field)
+		switch n.Op {
+		case "!=":
+			return Not(f.compile(e, "=", n.Value))
+		case "!~":
+			return Not(f.compile(e, "~", n.Value))
+		default:
+			return f.compile(e, n.Op, n.Value)
+		}
+	}
+	return func(backendbeer.BeerResponse) bool { return false }
+}
+
+func lookupExprField(name string) (exprField, bool) {
+	name = strings.ToLower(name)
+	if canonical, ok := exprAliases[name]; ok {
+		name = canonical
+	}
+	f, ok := exprFields[name]
+	return f, ok
+}
+
+func exprFieldNames() string {
+	names := make([]string, 0, len(exprFields)+len(exprAliases))
+	for name := range exprFields {
+		names = append(names, name)
+	}
+	for name := range exprAliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// FilterExpr is a filter expression parsed and type checked once, when it
+// is built, so validation, cache keys and compilation share one AST. The
+// zero value is the empty expression.
+type FilterExpr struct {
+	src  string
+	node expr.Node
+	err  error
+}
+
+// NewFilterExpr parses src with ParseFilter; a parse error is reported by
+// BeerQuery.Validate.
+func NewFilterExpr(src string) FilterExpr {
+	fe := FilterExpr{src: src}
+	if src != "" {
+		fe.node, fe.err = ParseFilter(src)
+	}
+	return fe
+}
+
+// String is the expression as the client wrote it.
+func (fe FilterExpr) String() string {
+	return fe.src
+}
+
+func (fe FilterExpr) empty() bool {
+	return fe.src == ""
+}
+
+// key renders the canonical form of the expression for cache keys.
+func (fe FilterExpr) key() string {
+	if fe.node == nil {
+		return fe.src
+	}
+	return fe.node.String()
+}
+
+func (fe FilterExpr) validate(ve *ValidationError) {
+	if fe.err == nil {
+		return
+	}
+	var e *expr.Error
+	if errors.As(fe.err, &e) {
+		ve.Errors = append(ve.Errors, FieldError{Field: "q", Message: e.Error(), Position: e.Column})
+		return
+	}
+	ve.add("q", "%v", fe.err)
+}
@@ -0,0 +1,162 @@
+package beer
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/search"
+	"slices"
+	"strings"
+)
+
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
+// Synonyms maps a normalized food term to the terms it should also match.
+type Synonyms map[string][]string
+
+// NewSynonyms builds the dictionary from entries like
+// {"chicken": ["poultry", "hen"]}: the head term matches its synonyms and
+// every synonym matches the head term back.
+func NewSynonyms(entries map[string][]string) Synonyms {
+	syn := make(Synonyms)
+	link := func(from, to string) {
+		if from != "" && to != "" && from != to && !slices.Contains(syn[from], to) {
+			syn[from] = append(syn[from], to)
+		}
+	}
+	for head, alternatives := range entries {
+		head = normalizeFood(head)
+		for _, alt := range alternatives {
+			alt = normalizeFood(alt)
+			link(head, alt)
+			link(alt, head)
+		}
+	}
+	return syn
+}
+
+func normalizeFood(s string) string {
+	return strings.Join(search.Words(s), " ")
+}
+
+// foodPattern is one spelling of a requested food.
+type foodPattern struct {
+	text   string   // normalized, for substring matching
+	tokens []string // stemmed, for token matching
+	// substring is set for the requested food only; synonyms match whole
+	// words, so "ham" does not match "graham crackers".
+	substring bool
+}
+
+// matches reports whether the pairing contains the pattern either as a
+// substring or as a set of (stemmed) words, so "chickens" still matches
+// "Spicy chicken tikka masala".
+func (p foodPattern) matches(pairing string, pairingTokens []string) bool {
+	if p.text == "" {
+		return false
+	}
+	if p.substring && strings.Contains(pairing, p.text) {
+		return true
+	}
+	if len(p.tokens) == 0 {
+		return false
+	}
+	for _, t := range p.tokens {
+		if !slices.Contains(pairingTokens, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// foodTerm is a requested food with the patterns of its synonyms.
+type foodTerm []foodPattern
+
+func newFoodTerm(food string, syn Synonyms) foodTerm {
+	food = normalizeFood(food)
+	term := foodTerm{{text: food, tokens: search.Tokenize(food), substring: true}}
+	for _, alt := range syn[food] {
+		term = append(term, foodPattern{text: alt, tokens: search.Tokenize(alt)})
+	}
+	return term
+}
+
+func (ft foodTerm) matchesAny(pairings []string, tokens [][]string) bool {
+	for i, pairing := range pairings {
+		for _, p := range ft {
+			if p.matches(pairing, tokens[i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PairsWithFoods matches beers whose food pairings mention any or all of
+// foods, depending on match. Matching ignores case, accepts substrings and
+// word matches and expands every food with its synonyms.
+func PairsWithFoods(foods []string, match string, syn Synonyms) Predicate {
+	return pairsWithFoods(foods, match, syn, nil)
+}
+
+// foodPairings are the pairings of one beer, normalized and tokenized.
+type foodPairings struct {
+	texts  []string
+	tokens [][]string
+}
+
+func newFoodPairings(b backendbeer.BeerResponse) foodPairings {
+	fp := foodPairings{
+		texts:  make([]string, len(b.FoodPairing)),
+		tokens: make([][]string, len(b.FoodPairing)),
+	}
+	for i, pairing := range b.FoodPairing {
+		fp.texts[i] = normalizeFood(pairing)
+		fp.tokens[i] = search.Tokenize(pairing)
+	}
+	return fp
+}
+
+// pairsWithFoods is PairsWithFoods reading the pairings of beers found in
+// pairings, keyed by beer ID, instead of tokenizing them on every call.
+func pairsWithFoods(foods []string, match string, syn Synonyms, pairings map[int]foodPairings) Predicate {
+	terms := make([]foodTerm, 0, len(foods))
+	for _, f := range foods {
+		terms = append(terms, newFoodTerm(f, syn))
+	}
+
+	return func(b backendbeer.BeerResponse) bool {
+		fp, ok := pairings[b.ID]
+		if !ok {
+			fp = newFoodPairings(b)
+		}
+
+		for _, t := range terms {
+			ok := t.matchesAny(fp.texts, fp.tokens)
+			if ok && match == MatchAny {
+				return true
+			}
+			if !ok && match == MatchAll {
+				return false
+			}
+		}
+		return match == MatchAll
+	}
+}
+
+// PairsWith matches beers with food in their food pairings.
+func PairsWith(food string) Predicate {
+	return PairsWithFoods([]string{food}, MatchAny, nil)
+}
+
+func validateFoods(foods []string, match string, ve *ValidationError) {
+	for _, f := range foods {
+		if normalizeFood(f) == "" {
+			ve.add("hasFood", "food must contain letters or digits, got %q", f)
+		}
+	}
+	if match != "" && match != MatchAny && match != MatchAll {
+		ve.add("match", "must be %s or %s", MatchAny, MatchAll)
+	}
+}
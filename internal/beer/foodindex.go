@@ -0,0 +1,93 @@
+package beer
+
+import (
+	"cmp"
+	backendbeer "interview-go/backend/client"
+	"slices"
+)
+
+// foodIndex is an inverted index from normalized food pairings to the
+// beers pairing with them. It belongs to a catalog snapshot, so it is
+// rebuilt whenever the catalog is refreshed.
+type foodIndex struct {
+	beers  map[string][]int // normalized pairing -> positions in Beers
+	counts []ValueCount     // every pairing, most paired first
+}
+
+func newFoodIndex(beers []backendbeer.BeerResponse) foodIndex {
+	idx := foodIndex{beers: map[string][]int{}}
+	for i, b := range beers {
+		for _, fp := range b.FoodPairing {
+			food := normalizeFood(fp)
+			if food == "" {
+				continue
+			}
+			// a beer counts once even when it repeats a pairing
+			if positions := idx.beers[food]; len(positions) == 0 || positions[len(positions)-1] != i {
+				idx.beers[food] = append(positions, i)
+			}
+		}
+	}
+
+	idx.counts = make([]ValueCount, 0, len(idx.beers))
+	for food, positions := range idx.beers {
+		idx.counts = append(idx.counts, ValueCount{Value: food, Count: len(positions)})
+	}
+	slices.SortFunc(idx.counts, func(a, b ValueCount) int {
+		if r := cmp.Compare(b.Count, a.Count); r != 0 {
+			return r
+		}
+		return cmp.Compare(a.Value, b.Value)
+	})
+	return idx
+}
+
+// GetFoods lists the normalized food pairings of the snapshot with the
+// number of beers for each, most paired first.
+func (s *service) GetFoods() ([]ValueCount, error) {
+	cat, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return cat.foods.counts, nil
+}
+
+// validateFoodPage checks the arguments of GetBeersByFood.
+func validateFoodPage(food string, page Page) error {
+	ve := &ValidationError{}
+	page.validate(ve)
+	if normalizeFood(food) == "" {
+		ve.add("food", "must contain letters or digits, got %q", food)
+	}
+	return ve.orNil()
+}
+
+// GetBeersByFood returns the requested page of beers pairing with food,
+// in catalog order. food is normalized like the pairings listed by
+// GetFoods; an unknown food is ErrFoodNotFound.
+func (s *service) GetBeersByFood(food string, page Page) (BeerPage, error) {
+	if err := validateFoodPage(food, page); err != nil {
+		return BeerPage{}, err
+	}
+
+	cat, status, err := s.load()
+	if err != nil {
+		return BeerPage{}, err
+	}
+
+	positions, ok := cat.foods.beers[normalizeFood(food)]
+	if !ok {
+		return BeerPage{}, ErrFoodNotFound
+	}
+
+	beers := make([]backendbeer.BeerResponse, len(positions))
+	for i, p := range positions {
+		beers[i] = cat.Beers[p]
+	}
+	p, err := paginate(beers, page, cat.Version, "food:"+normalizeFood(food))
+	if err != nil {
+		return BeerPage{}, err
+	}
+	p.Snapshot = cat.info(status)
+	return p, nil
+}
@@ -1,130 +1,799 @@
 package beer
 
 import (
-	backendbeer "interview-go/backend/client"
+	"cmp"
+	"errors"
+	"fmt"
+	"interview-go/config"
+	"interview-go/internal/cache"
+	"math"
 	"net/http"
-	"slices"
-	"sort"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
 type HTTPHandler interface {
 	ListAllBeers(c echo.Context) error
-	//TODO
 	FilteredBeers(c echo.Context) error
+	GetBeer(c echo.Context) error
+	BeersByIDs(c echo.Context) error
+	SearchBeers(c echo.Context) error
+	AdvancedSearch(c echo.Context) error
+	Stats(c echo.Context) error
+	SimilarBeers(c echo.Context) error
+	Foods(c echo.Context) error
+	FoodBeers(c echo.Context) error
+	CompareBeers(c echo.Context) error
 }
 
 type beerHandler struct {
 	service Service
+
+	// responses holds encoded response bodies; nil when disabled in config
+	responses   cache.Cache
+	responseTTL time.Duration
 }
 
 func (h *beerHandler) FilteredBeers(c echo.Context) error {
-	var err error
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+
+	q := h.service.GetDefaultQuery()
+	err = bindQuery(c, &q)
+	if err != nil {
+		return err
+	}
+
+	q.Page, err = bindPage(c)
+	if err != nil {
+		return err
+	}
+
+	q.Fields, err = bindFields(c)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	q.Facets, err = bindFacets(c)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	if err := q.Validate(); err != nil {
+		return serviceError(err)
+	}
+
+	key, err := h.snapshotKey("filtered:" + q.Key() + "|fields=" + fieldsKey(q.Fields))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, format); ok {
+		return err
+	}
+
+	resp, err := h.service.GetFilteredBeers(q)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderPage(c, key, format, resp, q.Fields, q.Params())
+}
+
+// Stats serves /beer/stats. It takes the filters of /beer/getFiltered but
+// none of its defaults, so without parameters it covers the whole catalog.
+func (h *beerHandler) Stats(c echo.Context) error {
+	var q BeerQuery
+	if err := bindQuery(c, &q); err != nil {
+		return err
+	}
+	q.Sort = nil
+
+	top := DefaultStatsTop
+	if raw := c.QueryParam("top"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > MaxStatsTop {
+			ve := &ValidationError{}
+			ve.add("top", "must be a number between 1 and %d, got %q", MaxStatsTop, raw)
+			return serviceError(ve)
+		}
+		top = n
+	}
+
+	if err := q.Validate(); err != nil {
+		return serviceError(err)
+	}
+
+	key, err := h.snapshotKey("stats:" + q.Filters.String() + "|top=" + strconv.Itoa(top))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, FormatJSON); ok {
+		return err
+	}
+
+	resp, err := h.service.GetStats(q, top)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderJSON(c, key, resp)
+}
 
-	filters := h.service.GetDefaultFilters()
+// bindQuery overrides the filters and sort of q with the query parameters
+// shared by the filtered list and the statistics endpoints.
+func bindQuery(c echo.Context, q *BeerQuery) error {
+	var err error
 
+	// includeIpa is kept for old clients as an alias of style=ipa
 	includeIpa := c.QueryParam("includeIpa")
 	if includeIpa != "" {
-		filters.IncludeIpa, err = strconv.ParseBool(includeIpa)
+		ipa, err := strconv.ParseBool(includeIpa)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
+		q.Filters.Styles = nil
+		if ipa {
+			q.Filters.Styles = []Style{StyleIPA}
+		}
+	}
+
+	// style takes precedence over includeIpa; "all" disables the filter
+	style := c.QueryParam("style")
+	if style != "" {
+		q.Filters.Styles = ParseStyles(style)
+		if len(q.Filters.Styles) == 1 && q.Filters.Styles[0] == "all" {
+			q.Filters.Styles = nil
+		}
 	}
 
 	year := c.QueryParam("year")
 	if year != "" {
-		filters.Year, err = strconv.Atoi(year)
+		q.Filters.Year, err = strconv.Atoi(year)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
 	}
 
+	// hasFood takes a comma separated list, combined as set by match
 	food := c.QueryParam("hasFood")
 	if food != "" {
-		filters.HasFood = food
+		q.Filters.Foods = nil
+		for _, f := range strings.Split(food, ",") {
+			q.Filters.Foods = append(q.Filters.Foods, strings.TrimSpace(f))
+		}
 	}
 
+	match := c.QueryParam("match")
+	if match != "" {
+		q.Filters.FoodMatch = strings.ToLower(match)
+	}
+
+	if err := bindRanges(c, &q.Filters); err != nil {
+		return serviceError(err)
+	}
+
+	if err := bindIngredients(c, &q.Filters.Ingredients); err != nil {
+		return serviceError(err)
+	}
+
+	// q is a filter expression, e.g. abv >= 5 and not style = "stout"
+	q.Filters.Expr = NewFilterExpr(strings.TrimSpace(c.QueryParam("q")))
+
 	abvSortOrder := c.QueryParam("abvSortOrder")
 	if abvSortOrder != "" {
-		filters.AbvSortOrder = abvSortOrder
+		switch strings.ToLower(abvSortOrder) {
+		case "asc":
+			q.Sort = []SortKey{{Field: "abv"}}
+		case "desc":
+			q.Sort = []SortKey{{Field: "abv", Desc: true}}
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, "abvSortOrder must be asc or desc")
+		}
+	}
+
+	// sort takes precedence over the legacy abvSortOrder
+	sort := c.QueryParam("sort")
+	if sort != "" {
+		q.Sort, err = ParseSort(sort)
+		if err != nil {
+			return serviceError(err)
+		}
+	}
+
+	return nil
+}
+
+// serviceError maps service errors to HTTP errors.
+func serviceError(err error) error {
+	var ve *ValidationError
+	switch {
+	case errors.As(err, &ve):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	case err == ErrBeerNotFound, err == ErrFoodNotFound:
+		return echo.NewHTTPError(http.StatusNotFound, err)
+	case err == ErrRateLimitExceeded:
+		return echo.NewHTTPError(http.StatusTooManyRequests, err)
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
+}
 
-	var filteredResp []backendbeer.BeerResponse
+// NewHandler builds the beer handler. responses caches encoded response
+// bodies and may be nil to always encode on the fly.
+func NewHandler(service Service, responses cache.Cache, cfg *config.Configuration) HTTPHandler {
+	return &beerHandler{
+		service:     service,
+		responses:   responses,
+		responseTTL: cfg.Cache.TTL,
+	}
+}
+
+func (h *beerHandler) ListAllBeers(c echo.Context) error {
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
 
-	resp, err := h.service.GetFilteredBeers(filters.String())
+	page, err := bindPage(c)
 	if err != nil {
-		if err == ErrRateLimitExceeded {
-			return echo.NewHTTPError(http.StatusTooManyRequests, err)
+		return err
+	}
+
+	fields, err := bindFields(c)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	if err := page.Validate(); err != nil {
+		return serviceError(err)
+	}
+
+	key, err := h.snapshotKey("all|" + page.key() + "|fields=" + fieldsKey(fields))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, format); ok {
+		return err
+	}
+
+	resp, err := h.service.GetAllBeers(page)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderPage(c, key, format, resp, fields, nil)
+}
+
+func (h *beerHandler) GetBeer(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	key, err := h.snapshotKey("beer:" + strconv.Itoa(id))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, FormatJSON); ok {
+		return err
+	}
+
+	resp, err := h.service.GetBeer(id)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderJSON(c, key, resp)
+}
+
+// SimilarBeers serves recommendations like /beer/7/similar?limit=5.
+func (h *beerHandler) SimilarBeers(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	limit := DefaultSimilarLimit
+	if raw := c.QueryParam("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > MaxSimilarLimit {
+			ve := &ValidationError{}
+			ve.add("limit", "must be a number between 1 and %d, got %q", MaxSimilarLimit, raw)
+			return serviceError(ve)
 		}
+		limit = n
+	}
+
+	fields, err := bindFields(c)
+	if err != nil {
+		return serviceError(err)
+	}
+	if len(fields) > 0 {
+		fields = append(fields, "score")
+	}
+
+	key, err := h.snapshotKey("similar:" + strconv.Itoa(id) + "|limit=" + strconv.Itoa(limit) + "|fields=" + fieldsKey(fields))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, FormatJSON); ok {
+		return err
+	}
+
+	resp, err := h.service.GetSimilarBeers(id, limit)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	body, err := projectItems(resp, fields)
+	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
+	return h.renderJSON(c, key, body)
+}
+
+// Foods lists the food pairings with their beer counts.
+func (h *beerHandler) Foods(c echo.Context) error {
+	key, err := h.snapshotKey("foods")
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, FormatJSON); ok {
+		return err
+	}
+
+	resp, err := h.service.GetFoods()
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderJSON(c, key, resp)
+}
+
+// FoodBeers serves the beers of one pairing like /foods/grilled%20chicken/beers.
+func (h *beerHandler) FoodBeers(c echo.Context) error {
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+
+	// echo leaves the parameter escaped when the path holds encoded slashes
+	food := c.Param("food")
+	if unescaped, err := url.PathUnescape(food); err == nil {
+		food = unescaped
+	}
+
+	page, err := bindPage(c)
+	if err != nil {
+		return err
+	}
+
+	fields, err := bindFields(c)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	if err := validateFoodPage(food, page); err != nil {
+		return serviceError(err)
+	}
+
+	key, err := h.snapshotKey("food:" + normalizeFood(food) + "|" + page.key() + "|fields=" + fieldsKey(fields))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, format); ok {
+		return err
+	}
+
+	resp, err := h.service.GetBeersByFood(food, page)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderPage(c, key, format, resp, fields, map[string]string{"food": normalizeFood(food)})
+}
+
+// BeersByIDs serves batch lookups like /beer?ids=1,2,3.
+func (h *beerHandler) BeersByIDs(c echo.Context) error {
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
+	}
+
+	ids, err := parseIDs(c.QueryParam("ids"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	fields, err := bindFields(c)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	resp, err := h.service.GetBeersByIDs(ids)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	if format != FormatJSON {
+		return streamExport(c, format, resp, fields)
+	}
 
 	if len(resp) == 0 {
 		return c.NoContent(http.StatusNoContent)
 	}
 
-	for _, v := range resp {
-		if filters.IncludeIpa && !strings.Contains(strings.ToLower(v.Name), "ipa") {
-			continue
+	return renderProjected(c, resp, fields, nil)
+}
+
+// CompareBeers serves side-by-side comparisons like /beer/compare?ids=1,7,42.
+func (h *beerHandler) CompareBeers(c echo.Context) error {
+	ids, err := parseIDs(c.QueryParam("ids"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	distinct, err := distinctIDs(ids)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	parts := make([]string, len(distinct))
+	for i, id := range distinct {
+		parts[i] = strconv.Itoa(id)
+	}
+	key, err := h.snapshotKey("compare:" + strings.Join(parts, ","))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, FormatJSON); ok {
+		return err
+	}
+
+	resp, err := h.service.CompareBeers(ids)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderJSON(c, key, resp)
+}
+
+// parseIDs parses a comma separated list of beer IDs.
+func parseIDs(s string) ([]int, error) {
+	if s == "" {
+		return nil, errors.New("ids is required")
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) > MaxPageLimit {
+		return nil, fmt.Errorf("at most %d ids are allowed", MaxPageLimit)
+	}
+
+	ids := make([]int, 0, len(parts))
+	for _, p := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
 		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// bindPage reads the limit, offset and cursor query parameters.
+func bindPage(c echo.Context) (Page, error) {
+	var (
+		page Page
+		err  error
+	)
 
-		if !(extractYear(v.FirstBrewed) > filters.Year) {
-			continue
+	limit := c.QueryParam("limit")
+	if limit != "" {
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return page, echo.NewHTTPError(http.StatusBadRequest, err)
 		}
+	}
 
-		if !slices.Contains(v.FoodPairing, strings.ToLower(filters.HasFood)) {
-			continue
+	offset := c.QueryParam("offset")
+	if offset != "" {
+		page.Offset, err = strconv.Atoi(offset)
+		if err != nil {
+			return page, echo.NewHTTPError(http.StatusBadRequest, err)
 		}
+	}
+
+	page.Cursor = c.QueryParam("cursor")
 
-		filteredResp = append(filteredResp, v)
+	return page, nil
+}
+
+// SearchBeers serves full-text search like /beer/search?q=citrus+hazy.
+func (h *beerHandler) SearchBeers(c echo.Context) error {
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
 	}
 
-	if strings.ToLower(filters.AbvSortOrder) == "desc" {
-		sort.Slice(filteredResp, func(i, j int) bool {
-			return filteredResp[i].ABV > filteredResp[j].ABV
-		})
-	} else if strings.ToLower(filters.AbvSortOrder) == "asc" {
-		sort.Slice(filteredResp, func(i, j int) bool {
-			return filteredResp[i].ABV < filteredResp[j].ABV
-		})
+	page, err := bindPage(c)
+	if err != nil {
+		return err
 	}
 
-	return c.JSON(http.StatusOK, filteredResp)
+	fields, err := bindFields(c)
+	if err != nil {
+		return serviceError(err)
+	}
 
+	facets, err := bindFacets(c)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	text := c.QueryParam("q")
+	resp, err := h.service.SearchBeers(text, page, facets)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	if len(fields) > 0 {
+		// the relevance score is always kept next to the projected fields
+		fields = append(fields, "score")
+	}
+
+	if format != FormatJSON {
+		setPageHeaders(c, resp.Total, resp.Next, resp.Prev)
+		return streamExport(c, format, resp.Hits, fields)
+	}
+
+	if wantsEnvelope(c) {
+		body, err := projectItems(resp.Hits, fields)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		return renderEnvelope(c, body, resp.meta(map[string]string{"q": text}), resp.Next, resp.Prev)
+	}
+
+	setPageHeaders(c, resp.Total, resp.Next, resp.Prev)
+	if len(resp.Hits) == 0 {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return renderProjected(c, resp.Hits, fields, resp.Facets)
 }
 
-func NewHandler(service Service) HTTPHandler {
-	return &beerHandler{
-		service: service,
+// AdvancedSearch serves POST /beer/search with a SearchRequest body. The
+// cursor query parameter of the Link header overrides the body cursor, so
+// the next page is the same POST to the linked URL.
+func (h *beerHandler) AdvancedSearch(c echo.Context) error {
+	format, err := negotiateFormat(c)
+	if err != nil {
+		return err
 	}
+
+	req, err := DecodeSearchRequest(c.Request().Body)
+	if err != nil {
+		return serviceError(err)
+	}
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		req.Cursor = cursor
+	}
+
+	q := req.Query()
+	if err := q.Validate(); err != nil {
+		return serviceError(err)
+	}
+	key, err := h.snapshotKey("search:" + q.Key() + "|fields=" + fieldsKey(q.Fields))
+	if err != nil {
+		return serviceError(err)
+	}
+	if ok, err := h.serveEncoded(c, key, format); ok {
+		return err
+	}
+
+	resp, err := h.service.GetFilteredBeers(q)
+	if err != nil {
+		return serviceError(err)
+	}
+
+	return h.renderPage(c, key, format, resp, q.Fields, q.Params())
 }
 
-func (h *beerHandler) ListAllBeers(c echo.Context) error {
-	resp, err := h.service.GetAllBeers()
+// renderPage writes the page with its X-Total-Count and Link headers,
+// projected on fields when any are given and wrapped with its facets when
+// they were requested. Clients opting into envelopes get the page in an
+// Envelope with filters as its effective filters.
+func (h *beerHandler) renderPage(c echo.Context, key, format string, page BeerPage, fields []string, filters map[string]string) error {
+	if format != FormatJSON {
+		setPageHeaders(c, page.Total, page.Next, page.Prev)
+		return streamExport(c, format, page.Beers, fields)
+	}
+
+	body, err := projectItems(page.Beers, fields)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	if len(resp) == 0 {
+	if wantsEnvelope(c) {
+		return renderEnvelope(c, body, page.meta(filters), page.Next, page.Prev)
+	}
+
+	setPageHeaders(c, page.Total, page.Next, page.Prev)
+	if len(page.Beers) == 0 {
 		return c.NoContent(http.StatusNoContent)
 	}
+	return h.renderJSON(c, key, withFacets(body, page.Facets))
+}
 
-	return c.JSON(http.StatusOK, resp)
+// renderProjected writes items without caching, projected on fields when
+// any are given.
+func renderProjected[T any](c echo.Context, items []T, fields []string, facets Facets) error {
+	body, err := projectItems(items, fields)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, withFacets(body, facets))
+}
+
+// projectItems returns items as they are, or projected on fields.
+func projectItems[T any](items []T, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+	return project(items, fields)
+}
+
+// facetedResponse is the body of a list requested with facets. Without
+// facets lists stay bare arrays.
+type facetedResponse struct {
+	Results any    `json:"results"`
+	Facets  Facets `json:"facets"`
+}
+
+func withFacets(body any, facets Facets) any {
+	if facets == nil {
+		return body
+	}
+	return facetedResponse{Results: body, Facets: facets}
 }
 
-func extractYear(firstBrewed string) int {
-	// Acceptă"YYYY-MM"
-	s := strings.TrimSpace(firstBrewed)
-	if len(s) < 4 {
-		return 0
+// bindFacets reads the opt-in facets parameter, e.g. facets=food,style.
+func bindFacets(c echo.Context) ([]string, error) {
+	facets := ParseFacets(c.QueryParam("facets"))
+	ve := &ValidationError{}
+	validateFacets(facets, ve)
+	return facets, ve.orNil()
+}
+
+// bindFields reads the comma separated fields projection, e.g.
+// fields=id,name,ingredients.hops.name.
+func bindFields(c echo.Context) ([]string, error) {
+	raw := c.QueryParam("fields")
+	if raw == "" {
+		return nil, nil
 	}
-	// ia doar primele 4 caractere (dacă sunt cifre)
-	yearStr := s[:4]
-	if y, err := strconv.Atoi(yearStr); err == nil {
-		return y
+
+	var fields []string
+	for _, f := range strings.Split(raw, ",") {
+		fields = append(fields, strings.TrimSpace(f))
+	}
+
+	ve := &ValidationError{}
+	validateFields(fields, ve)
+	return fields, ve.orNil()
+}
+
+// setPageHeaders sets X-Total-Count and the RFC 8288 Link header.
+func setPageHeaders(c echo.Context, total int, next, prev string) {
+	header := c.Response().Header()
+	header.Set("X-Total-Count", strconv.Itoa(total))
+	if links := pageLinks(c, next, prev); links != "" {
+		header.Set("Link", links)
 	}
-	return 0
+}
+
+// pageLinks builds the Link header value for the next and prev pages.
+func pageLinks(c echo.Context, next, prev string) string {
+	var links []string
+	if next != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(c, next)))
+	}
+	if prev != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(c, prev)))
+	}
+	return strings.Join(links, ", ")
+}
+
+// pageURL is the request URL moved to the page at cursor.
+func pageURL(c echo.Context, cursor string) string {
+	req := c.Request()
+	q := req.URL.Query()
+	q.Del("offset")
+	q.Set("cursor", cursor)
+	return fmt.Sprintf("%s://%s%s?%s", c.Scheme(), req.Host, req.URL.Path, q.Encode())
+}
+
+// bindRanges reads the abv_* and brewed_* range parameters. Each bound
+// comes in an exclusive and an inclusive flavour, e.g. abv_gt and abv_gte.
+func bindRanges(c echo.Context, f *BeerFilter) error {
+	ve := &ValidationError{}
+
+	bindBound(c, ve, &f.ABV.Lower, "abv_gt", "abv_gte", parseABV)
+	bindBound(c, ve, &f.ABV.Upper, "abv_lt", "abv_lte", parseABV)
+	bindBound(c, ve, &f.Brewed.Lower, "brewed_after", "brewed_from", parseMonth)
+	bindBound(c, ve, &f.Brewed.Upper, "brewed_before", "brewed_until", parseMonth)
+
+	return ve.orNil()
+}
+
+func bindIngredients(c echo.Context, f *IngredientFilter) error {
+	ve := &ValidationError{}
+
+	f.Hops = strings.TrimSpace(c.QueryParam("hops"))
+	f.HopAttribute = strings.TrimSpace(c.QueryParam("hop_attribute"))
+	f.Malt = strings.TrimSpace(c.QueryParam("malt"))
+	f.Yeast = strings.TrimSpace(c.QueryParam("yeast"))
+
+	bindAmount(c, ve, &f.MinHops, "hops_min")
+	bindAmount(c, ve, &f.MinMalt, "malt_min")
+
+	return ve.orNil()
+}
+
+// bindAmount reads a minimum amount such as "20g" or "1.5kg" in grams.
+func bindAmount(c echo.Context, ve *ValidationError, grams *float64, param string) {
+	raw := c.QueryParam(param)
+	if raw == "" {
+		return
+	}
+	g, err := ParseAmount(raw)
+	if err != nil {
+		ve.add(param, "%v", err)
+		return
+	}
+	*grams = g
+}
+
+func bindBound[T cmp.Ordered](c echo.Context, ve *ValidationError, b *Bound[T], exclusive, inclusive string, parse func(string) (T, error)) {
+	ex, in := c.QueryParam(exclusive), c.QueryParam(inclusive)
+	if ex != "" && in != "" {
+		ve.add(inclusive, "cannot be combined with %s", exclusive)
+		return
+	}
+
+	param, raw := exclusive, ex
+	if in != "" {
+		param, raw = inclusive, in
+	}
+	if raw == "" {
+		return
+	}
+
+	v, err := parse(raw)
+	if err != nil {
+		ve.add(param, "%v", err)
+		return
+	}
+	*b = Bound[T]{Value: v, Inclusive: in != "", Set: true}
+}
+
+func parseABV(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("must be a number, got %q", s)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("must be a finite number, got %q", s)
+	}
+	return v, nil
+}
+
+// parseMonth keeps the raw value; the format is checked by BeerQuery.Validate.
+func parseMonth(s string) (string, error) {
+	return strings.TrimSpace(s), nil
 }
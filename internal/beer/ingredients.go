@@ -0,0 +1,176 @@
+package beer
+
+import (
+	"fmt"
+	backendbeer "interview-go/backend/client"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Hop attributes used by the upstream recipes.
+var hopAttributes = map[string]string{
+	"bitter":  "bitter",
+	"aroma":   "aroma",
+	"flavour": "flavour",
+	"flavor":  "flavour",
+}
+
+// gramsPerUnit normalizes recipe amounts to grams.
+var gramsPerUnit = map[string]float64{
+	"":          1,
+	"g":         1,
+	"gram":      1,
+	"grams":     1,
+	"kg":        1000,
+	"kilogram":  1000,
+	"kilograms": 1000,
+	"oz":        28.349523125,
+	"ounce":     28.349523125,
+	"ounces":    28.349523125,
+	"lb":        453.59237,
+	"lbs":       453.59237,
+	"pound":     453.59237,
+	"pounds":    453.59237,
+}
+
+// IngredientFilter matches beers by their recipe. Names match
+// case-insensitive substrings; a zero field matches everything.
+type IngredientFilter struct {
+	Hops         string
+	HopAttribute string
+	Malt         string
+	Yeast        string
+	MinHops      float64 // grams of one matching hop addition
+	MinMalt      float64 // grams of one matching malt
+}
+
+func (f IngredientFilter) IsZero() bool {
+	return f == IngredientFilter{}
+}
+
+func (f IngredientFilter) String() string {
+	return fmt.Sprintf("hops=%s|hop_attribute=%s|malt=%s|yeast=%s|hops_min=%g|malt_min=%g",
+		strings.ToLower(f.Hops), normalizeHopAttribute(f.HopAttribute),
+		strings.ToLower(f.Malt), strings.ToLower(f.Yeast), f.MinHops, f.MinMalt)
+}
+
+// Grams converts an upstream amount to grams; ok is false for unknown units.
+func Grams(a backendbeer.Amount) (g float64, ok bool) {
+	factor, ok := gramsPerUnit[strings.ToLower(strings.TrimSpace(a.Unit))]
+	if !ok {
+		return 0, false
+	}
+	return a.Value * factor, true
+}
+
+// ParseAmount reads an amount like "20g", "0.5 kg" or "2oz" as grams.
+// A bare number is taken as grams.
+func ParseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != '-' && r != '+'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("must be an amount like 20g or 0.5kg, got %q", s)
+	}
+	g, ok := Grams(backendbeer.Amount{Value: v, Unit: s[i:]})
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", strings.TrimSpace(s[i:]))
+	}
+	return g, nil
+}
+
+func normalizeHopAttribute(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if a, ok := hopAttributes[s]; ok {
+		return a
+	}
+	return s
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(strings.TrimSpace(substr)))
+}
+
+// atLeast reports whether amount reaches min grams; amounts in unknown
+// units only pass when there is no minimum.
+func atLeast(amount backendbeer.Amount, min float64) bool {
+	if min == 0 {
+		return true
+	}
+	g, ok := Grams(amount)
+	return ok && g >= min
+}
+
+// UsesHop matches beers with one hop addition satisfying every hop
+// criterion of f together.
+func UsesHop(f IngredientFilter) Predicate {
+	attribute := normalizeHopAttribute(f.HopAttribute)
+	return func(b backendbeer.BeerResponse) bool {
+		for _, h := range b.Ingredients.Hops {
+			if f.Hops != "" && !containsFold(h.Name, f.Hops) {
+				continue
+			}
+			if attribute != "" && normalizeHopAttribute(h.Attribute) != attribute {
+				continue
+			}
+			if atLeast(h.Amount, f.MinHops) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// UsesMalt matches beers with a malt containing name in at least min grams.
+func UsesMalt(name string, min float64) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		for _, m := range b.Ingredients.Malt {
+			if (name == "" || containsFold(m.Name, name)) && atLeast(m.Amount, min) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// UsesYeast matches beers whose yeast contains name, e.g. "1056".
+func UsesYeast(name string) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		return containsFold(b.Ingredients.Yeast, name)
+	}
+}
+
+func (f IngredientFilter) predicates() []Predicate {
+	var out []Predicate
+	if f.Hops != "" || f.HopAttribute != "" || f.MinHops > 0 {
+		out = append(out, UsesHop(f))
+	}
+	if f.Malt != "" || f.MinMalt > 0 {
+		out = append(out, UsesMalt(f.Malt, f.MinMalt))
+	}
+	if f.Yeast != "" {
+		out = append(out, UsesYeast(f.Yeast))
+	}
+	return out
+}
+
+func (f IngredientFilter) validate(ve *ValidationError) {
+	if f.HopAttribute != "" {
+		if _, ok := hopAttributes[strings.ToLower(strings.TrimSpace(f.HopAttribute))]; !ok {
+			ve.add("hop_attribute", "must be bitter, flavour or aroma, got %q", f.HopAttribute)
+		}
+	}
+	if f.MinHops < 0 {
+		ve.add("hops_min", "must not be negative")
+	}
+	if f.MinMalt < 0 {
+		ve.add("malt_min", "must not be negative")
+	}
+}
@@ -0,0 +1,163 @@
+package beer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	backendbeer "interview-go/backend/client"
+)
+
+// Page selects a window of the result; a zero Limit means no limit.
+// A Cursor from a previous BeerPage replaces Offset.
+type Page struct {
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// BeerPage is one page of a list result.
+type BeerPage struct {
+	Beers  []backendbeer.BeerResponse
+	Total  int
+	Offset int
+	Limit  int
+	// Next and Prev are opaque cursors, empty at either end of the result.
+	Next string
+	Prev string
+	// Facets counts the whole result, not just the page; nil unless requested.
+	Facets Facets
+	// Snapshot is the catalog snapshot the page was cut from.
+	Snapshot SnapshotInfo
+}
+
+// cursor is the decoded form of Page.Cursor. It is bound to the catalog
+// snapshot version and to the query it pages through, so it keeps
+// pointing at the same beers until the snapshot changes and cannot be
+// replayed against another query.
+type cursor struct {
+	Version string `json:"v"`
+	Query   string `json:"q"`
+	Offset  int    `json:"o"`
+	Limit   int    `json:"l"`
+}
+
+func (cur cursor) encode() string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var cur cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, err
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return cur, err
+	}
+	if cur.Offset < 0 || cur.Limit < 0 || cur.Limit > MaxPageLimit {
+		return cur, fmt.Errorf("cursor out of range")
+	}
+	return cur, nil
+}
+
+func (p Page) validate(ve *ValidationError) {
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		ve.add("limit", "must be between 0 and %d", MaxPageLimit)
+	}
+	if p.Offset < 0 {
+		ve.add("offset", "must not be negative")
+	}
+	if p.Cursor != "" {
+		if p.Offset != 0 {
+			ve.add("cursor", "cannot be combined with offset")
+		}
+		if _, err := decodeCursor(p.Cursor); err != nil {
+			ve.add("cursor", "malformed cursor")
+		}
+	}
+}
+
+func (p Page) key() string {
+	return fmt.Sprintf("limit=%d|offset=%d|cursor=%s", p.Limit, p.Offset, p.Cursor)
+}
+
+// Validate reports an invalid page as a *ValidationError.
+func (p Page) Validate() error {
+	ve := &ValidationError{}
+	p.validate(ve)
+	return ve.orNil()
+}
+
+// pageWindow is the [Start, End) slice of a result selected by a Page.
+type pageWindow struct {
+	Start, End int
+	Limit      int
+	Next, Prev string
+}
+
+// queryHash is the short digest of a result key stored in cursors.
+func queryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:8])
+}
+
+// window resolves the page over a result of n items. query identifies
+// the result before paging. It expects a validated Page and fails when the
+// cursor was issued for another catalog version or another query.
+func window(n int, p Page, version, query string) (pageWindow, error) {
+	offset, limit := p.Offset, p.Limit
+	hash := queryHash(query)
+	if p.Cursor != "" {
+		cur, _ := decodeCursor(p.Cursor)
+		ve := &ValidationError{}
+		switch {
+		case cur.Version != version:
+			ve.add("cursor", "the catalog changed since the cursor was issued")
+		case cur.Query != hash:
+			ve.add("cursor", "the cursor was issued for another query")
+		}
+		if err := ve.orNil(); err != nil {
+			return pageWindow{}, err
+		}
+		offset = cur.Offset
+		if limit == 0 {
+			limit = cur.Limit
+		}
+	}
+
+	w := pageWindow{Start: min(offset, n), End: n, Limit: limit}
+	if limit > 0 && offset+limit < n {
+		w.End = offset + limit
+	}
+
+	if limit > 0 {
+		if w.End < n {
+			w.Next = cursor{Version: version, Query: hash, Offset: w.End, Limit: limit}.encode()
+		}
+		if offset > 0 {
+			w.Prev = cursor{Version: version, Query: hash, Offset: max(0, offset-limit), Limit: limit}.encode()
+		}
+	}
+
+	return w, nil
+}
+
+// paginate cuts the page selected by p out of all, the result of query.
+func paginate(all []backendbeer.BeerResponse, p Page, version, query string) (BeerPage, error) {
+	w, err := window(len(all), p, version, query)
+	if err != nil {
+		return BeerPage{}, err
+	}
+
+	return BeerPage{
+		Beers:  all[w.Start:w.End:w.End],
+		Total:  len(all),
+		Offset: w.Start,
+		Limit:  w.Limit,
+		Next:   w.Next,
+		Prev:   w.Prev,
+	}, nil
+}
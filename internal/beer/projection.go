@@ -0,0 +1,140 @@
+package beer
+
+import (
+	"bytes"
+	"encoding/json"
+	backendbeer "interview-go/backend/client"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// fieldTree is a parsed projection: every key is a JSON field to keep and
+// its subtree selects nested fields. An empty subtree keeps the whole value.
+type fieldTree map[string]fieldTree
+
+// parseFields builds the tree of dotted paths like "ingredients.hops.name".
+// Selecting a parent keeps it whole, whatever children are also selected.
+func parseFields(fields []string) fieldTree {
+	tree := fieldTree{}
+	for _, f := range fields {
+		node := tree
+		parts := strings.Split(f, ".")
+		for i, p := range parts {
+			child, seen := node[p]
+			if seen && len(child) == 0 {
+				break // already kept whole
+			}
+			if i == len(parts)-1 {
+				node[p] = fieldTree{}
+				break
+			}
+			if !seen {
+				child = fieldTree{}
+				node[p] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// projectRaw keeps the fields of tree in a JSON value. Arrays are projected
+// element by element, so "ingredients.hops.name" keeps every hop's name.
+func projectRaw(raw json.RawMessage, tree fieldTree) (json.RawMessage, error) {
+	if len(tree) == 0 {
+		return raw, nil
+	}
+
+	switch trimmed := bytes.TrimSpace(raw); {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+		for i, item := range items {
+			projected, err := projectRaw(item, tree)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = projected
+		}
+		return json.Marshal(items)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &all); err != nil {
+			return nil, err
+		}
+		out := make(map[string]json.RawMessage, len(tree))
+		for name, sub := range tree {
+			v, ok := all[name]
+			if !ok {
+				continue
+			}
+			projected, err := projectRaw(v, sub)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = projected
+		}
+		return json.Marshal(out)
+	default:
+		// scalars and null have no fields to select
+		return raw, nil
+	}
+}
+
+// project encodes every item of items keeping only fields.
+func project[T any](items []T, fields []string) ([]json.RawMessage, error) {
+	tree := parseFields(fields)
+	out := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		projected, err := projectRaw(raw, tree)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, projected)
+	}
+	return out, nil
+}
+
+// fieldsKey identifies a projection in cache keys; equal selections in a
+// different order share their encodings.
+func fieldsKey(fields []string) string {
+	sorted := slices.Clone(fields)
+	slices.Sort(sorted)
+	return strings.Join(slices.Compact(sorted), ",")
+}
+
+// validateFields checks every path against the JSON shape of BeerResponse.
+func validateFields(fields []string, ve *ValidationError) {
+	beerType := reflect.TypeOf(backendbeer.BeerResponse{})
+	for _, f := range fields {
+		if !validFieldPath(beerType, strings.Split(f, ".")) {
+			ve.add("fields", "unknown field %q", f)
+		}
+	}
+}
+
+func validFieldPath(t reflect.Type, path []string) bool {
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if len(path) == 0 {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name == path[0] && name != "" && name != "-" {
+			return validFieldPath(sf.Type, path[1:])
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package beer
+
+import (
+	backendbeer "interview-go/backend/client"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Predicate reports whether a beer is part of the result.
+type Predicate func(b backendbeer.BeerResponse) bool
+
+// Comparator orders two beers, returning a negative number when a comes
+// first, a positive one when b does and zero when they are equal.
+type Comparator func(a, b backendbeer.BeerResponse) int
+
+// Query is a compiled filter and sort order over a list of beers.
+// A nil Filter keeps every beer, a nil Sort keeps the input order.
+// Visit, when set, sees every match in the filter pass, so aggregates
+// like facets need no second walk over the result.
+type Query struct {
+	Filter Predicate
+	Sort   Comparator
+	Visit  func(b backendbeer.BeerResponse)
+}
+
+// Run returns the matching beers in query order. The input is not modified.
+func (q Query) Run(beers []backendbeer.BeerResponse) []backendbeer.BeerResponse {
+	out := make([]backendbeer.BeerResponse, 0, len(beers))
+	for _, b := range beers {
+		if q.Filter == nil || q.Filter(b) {
+			if q.Visit != nil {
+				q.Visit(b)
+			}
+			out = append(out, b)
+		}
+	}
+	if q.Sort != nil {
+		slices.SortStableFunc(out, q.Sort)
+	}
+	return out
+}
+
+func And(ps ...Predicate) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		for _, p := range ps {
+			if !p(b) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func Or(ps ...Predicate) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		for _, p := range ps {
+			if p(b) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func Not(p Predicate) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		return !p(b)
+	}
+}
+
+// NameContains matches beers whose name contains s, ignoring case.
+func NameContains(s string) Predicate {
+	s = strings.ToLower(s)
+	return func(b backendbeer.BeerResponse) bool {
+		return strings.Contains(strings.ToLower(b.Name), s)
+	}
+}
+
+// BrewedAfterYear matches beers first brewed in a year strictly after year.
+func BrewedAfterYear(year int) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		return extractYear(b.FirstBrewed) > year
+	}
+}
+
+// Reverse inverts the order of c.
+func (c Comparator) Reverse() Comparator {
+	return func(a, b backendbeer.BeerResponse) int {
+		return c(b, a)
+	}
+}
+
+// Then breaks ties of c with next.
+func (c Comparator) Then(next Comparator) Comparator {
+	return func(a, b backendbeer.BeerResponse) int {
+		if r := c(a, b); r != 0 {
+			return r
+		}
+		return next(a, b)
+	}
+}
+
+// Engine compiles a BeerQuery into a Query. Its zero value has no food
+// synonyms.
+type Engine struct {
+	Synonyms Synonyms
+
+	// pairings holds the tokenized food pairings of a catalog snapshot
+	pairings map[int]foodPairings
+}
+
+// forCatalog returns the engine reading food pairings precomputed by cat.
+func (e Engine) forCatalog(cat *catalog) Engine {
+	e.pairings = cat.pairings
+	return e
+}
+
+// Compile builds the predicates and comparator of a validated query.
+func (e Engine) Compile(q BeerQuery) Query {
+	return Query{
+		Filter: e.filter(q.Filters),
+		Sort:   sortComparator(q.Sort),
+	}
+}
+
+func (e Engine) filter(bf BeerFilter) Predicate {
+	var filters []Predicate
+	if bf.Year > 0 {
+		filters = append(filters, BrewedAfterYear(bf.Year))
+	}
+	if len(bf.Foods) > 0 {
+		match := bf.FoodMatch
+		if match == "" {
+			match = MatchAny
+		}
+		filters = append(filters, pairsWithFoods(bf.Foods, match, e.Synonyms, e.pairings))
+	}
+	if bf.Name != "" {
+		filters = append(filters, NameContains(bf.Name))
+	}
+	if len(bf.Styles) > 0 {
+		filters = append(filters, HasStyle(bf.Styles...))
+	}
+	if !bf.ABV.IsZero() {
+		filters = append(filters, ABVIn(bf.ABV))
+	}
+	if !bf.Brewed.IsZero() {
+		filters = append(filters, BrewedIn(bf.Brewed))
+	}
+	filters = append(filters, bf.Ingredients.predicates()...)
+	if !bf.Expr.empty() {
+		if bf.Expr.err != nil {
+			// unreachable for validated queries; match nothing rather than everything
+			return func(backendbeer.BeerResponse) bool { return false }
+		}
+		filters = append(filters, e.compileExpr(bf.Expr.node))
+	}
+	return And(filters...)
+}
+
+func extractYear(firstBrewed string) int {
+	// Acceptă"YYYY-MM"
+	s := strings.TrimSpace(firstBrewed)
+	if len(s) < 4 {
+		return 0
+	}
+	// ia doar primele 4 caractere (dacă sunt cifre)
+	yearStr := s[:4]
+	if y, err := strconv.Atoi(yearStr); err == nil {
+		return y
+	}
+	return 0
+}
@@ -0,0 +1,143 @@
+package beer
+
+import (
+	"cmp"
+	"fmt"
+	backendbeer "interview-go/backend/client"
+	"strings"
+	"time"
+)
+
+// monthLayout is the "yyyy-mm" format of FirstBrewed and BeerRequest dates.
+const monthLayout = "2006-01"
+
+// Bound is one optional end of a Range.
+type Bound[T cmp.Ordered] struct {
+	Value     T
+	Inclusive bool
+	Set       bool
+}
+
+// Range matches values between its bounds; an unset bound is open.
+type Range[T cmp.Ordered] struct {
+	Lower Bound[T]
+	Upper Bound[T]
+}
+
+func (r Range[T]) IsZero() bool {
+	return !r.Lower.Set && !r.Upper.Set
+}
+
+func (r Range[T]) Contains(v T) bool {
+	if r.Lower.Set {
+		if c := cmp.Compare(v, r.Lower.Value); c < 0 || (c == 0 && !r.Lower.Inclusive) {
+			return false
+		}
+	}
+	if r.Upper.Set {
+		if c := cmp.Compare(v, r.Upper.Value); c > 0 || (c == 0 && !r.Upper.Inclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// empty reports whether no value can satisfy both bounds.
+func (r Range[T]) empty() bool {
+	if !r.Lower.Set || !r.Upper.Set {
+		return false
+	}
+	c := cmp.Compare(r.Lower.Value, r.Upper.Value)
+	return c > 0 || (c == 0 && !(r.Lower.Inclusive && r.Upper.Inclusive))
+}
+
+// String renders the range in interval notation, e.g. "(5,7]".
+func (r Range[T]) String() string {
+	var sb strings.Builder
+	if r.Lower.Set && r.Lower.Inclusive {
+		sb.WriteByte('[')
+	} else {
+		sb.WriteByte('(')
+	}
+	if r.Lower.Set {
+		fmt.Fprint(&sb, r.Lower.Value)
+	}
+	sb.WriteByte(',')
+	if r.Upper.Set {
+		fmt.Fprint(&sb, r.Upper.Value)
+	}
+	if r.Upper.Set && r.Upper.Inclusive {
+		sb.WriteByte(']')
+	} else {
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+// ABVIn matches beers with an ABV inside r.
+func ABVIn(r Range[float64]) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		return r.Contains(b.ABV)
+	}
+}
+
+// BrewedIn matches beers first brewed in a "yyyy-mm" month inside r.
+func BrewedIn(r Range[string]) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		month := strings.TrimSpace(b.FirstBrewed)
+		if _, err := time.Parse(monthLayout, month); err != nil {
+			return false
+		}
+		return r.Contains(month)
+	}
+}
+
+func validateABVRange(r Range[float64], ve *ValidationError) {
+	if r.Lower.Set && r.Lower.Value < 0 {
+		ve.add(boundParam("abv", r.Lower, true), "must not be negative")
+	}
+	if r.empty() {
+		ve.add("abv", "lower bound %v is not below upper bound %v", r.Lower.Value, r.Upper.Value)
+	}
+}
+
+func validateBrewedRange(r Range[string], ve *ValidationError) {
+	valid := true
+	for _, b := range []struct {
+		bound Bound[string]
+		lower bool
+	}{{r.Lower, true}, {r.Upper, false}} {
+		if !b.bound.Set {
+			continue
+		}
+		if _, err := time.Parse(monthLayout, b.bound.Value); err != nil {
+			ve.add(boundParam("brewed", b.bound, b.lower), "must be a month in yyyy-mm format, got %q", b.bound.Value)
+			valid = false
+		}
+	}
+	if valid && r.empty() {
+		ve.add("brewed", "lower bound %s is not before upper bound %s", r.Lower.Value, r.Upper.Value)
+	}
+}
+
+// boundParam names the query parameter a bound comes from.
+func boundParam[T cmp.Ordered](field string, b Bound[T], lower bool) string {
+	switch {
+	case field == "brewed" && lower && b.Inclusive:
+		return "brewed_from"
+	case field == "brewed" && lower:
+		return "brewed_after"
+	case field == "brewed" && b.Inclusive:
+		return "brewed_until"
+	case field == "brewed":
+		return "brewed_before"
+	case lower && b.Inclusive:
+		return field + "_gte"
+	case lower:
+		return field + "_gt"
+	case b.Inclusive:
+		return field + "_lte"
+	default:
+		return field + "_lt"
+	}
+}
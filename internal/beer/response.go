@@ -0,0 +1,144 @@
+package beer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"interview-go/internal/cache"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// encodedResponse is a JSON body serialized once and served as-is on cache hits.
+type encodedResponse struct {
+	Body      []byte
+	ETag      string
+	ExpiresAt time.Time
+	Headers   map[string]string
+}
+
+// CacheSize lets a compressing cache skip encoding small bodies just to
+// measure them.
+func (e encodedResponse) CacheSize() int {
+	return len(e.Body) + len(e.ETag)
+}
+
+// cachedHeaders are the response headers stored next to an encoded body.
+var cachedHeaders = []string{"Link", "X-Total-Count"}
+
+// snapshotKey prefixes a response cache key with the catalog version, so
+// cached bodies, and the cursors in their Link headers, are not served
+// after a refresh. Loading the snapshot may reach the catalog cache and
+// the upstream, so callers validate the request first.
+func (h *beerHandler) snapshotKey(key string) (string, error) {
+	if h.responses == nil {
+		return key, nil
+	}
+	snap, err := h.service.Snapshot()
+	if err != nil {
+		return "", err
+	}
+	return snap.Version + "|" + key, nil
+}
+
+// serveEncoded writes the cached body stored under key, if any, for a
+// response in the negotiated format. It returns false when the handler has
+// to build the response itself, which is always the case for envelopes
+// and exports.
+func (h *beerHandler) serveEncoded(c echo.Context, key, format string) (bool, error) {
+	if h.responses == nil || wantsEnvelope(c) || format != FormatJSON {
+		return false, nil
+	}
+
+	cachedValue, err := h.responses.Get(key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrCacheMiss) && !errors.Is(err, cache.ErrTTLExpired) {
+			log.Println(err)
+		}
+		return false, nil
+	}
+
+	enc, ok := cachedValue.(encodedResponse)
+	if !ok || !time.Now().Before(enc.ExpiresAt) {
+		return false, nil
+	}
+
+	return true, writeEncoded(c, enc)
+}
+
+// renderJSON encodes v once, stores the body under key and writes it.
+// Without a response cache it falls back to a plain c.JSON.
+func (h *beerHandler) renderJSON(c echo.Context, key string, v any) error {
+	if h.responses == nil {
+		return c.JSON(http.StatusOK, v)
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	sum := sha256.Sum256(body)
+	enc := encodedResponse{
+		Body:      body,
+		ETag:      `"` + hex.EncodeToString(sum[:16]) + `"`,
+		ExpiresAt: time.Now().Add(h.responseTTL),
+	}
+	for _, name := range cachedHeaders {
+		if v := c.Response().Header().Get(name); v != "" {
+			if enc.Headers == nil {
+				enc.Headers = make(map[string]string)
+			}
+			enc.Headers[name] = v
+		}
+	}
+
+	if err := h.responses.Set(key, enc); err != nil {
+		// do not return here, just log it
+		log.Println(err)
+	}
+
+	return writeEncoded(c, enc)
+}
+
+func writeEncoded(c echo.Context, enc encodedResponse) error {
+	maxAge := int(math.Ceil(time.Until(enc.ExpiresAt).Seconds()))
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	header := c.Response().Header()
+	for name, v := range enc.Headers {
+		header.Set(name, v)
+	}
+	header.Set("ETag", enc.ETag)
+	header.Set(echo.HeaderCacheControl, "max-age="+strconv.Itoa(maxAge))
+
+	if etagMatches(c.Request().Header.Get("If-None-Match"), enc.ETag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSONBlob(http.StatusOK, enc.Body)
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag.
+// If-None-Match uses the weak comparison, so a W/ prefix is ignored.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
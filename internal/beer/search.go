@@ -0,0 +1,72 @@
+package beer
+
+import (
+	backendbeer "interview-go/backend/client"
+	"strings"
+)
+
+// SearchHit is a beer matching a full-text search with its BM25 score.
+type SearchHit struct {
+	backendbeer.BeerResponse
+	Score float64 `json:"score"`
+}
+
+// SearchPage is one page of search hits, best first.
+type SearchPage struct {
+	Hits   []SearchHit
+	Total  int
+	Offset int
+	Limit  int
+	Next   string
+	Prev   string
+	// Facets counts every hit, not just the page; nil unless requested.
+	Facets Facets
+	// Snapshot is the catalog snapshot that was searched.
+	Snapshot SnapshotInfo
+}
+
+// SearchBeers ranks the catalog against text over name, tagline,
+// description and brewer tips, with counts of the requested facets.
+func (s *service) SearchBeers(text string, page Page, facets []string) (SearchPage, error) {
+	ve := &ValidationError{}
+	if strings.TrimSpace(text) == "" {
+		ve.add("q", "must not be empty")
+	}
+	page.validate(ve)
+	validateFacets(facets, ve)
+	if err := ve.orNil(); err != nil {
+		return SearchPage{}, err
+	}
+
+	cat, status, err := s.load()
+	if err != nil {
+		return SearchPage{}, err
+	}
+
+	hits := cat.text.Search(text)
+	w, err := window(len(hits), page, cat.Version, "search:"+strings.TrimSpace(text))
+	if err != nil {
+		return SearchPage{}, err
+	}
+
+	out := make([]SearchHit, 0, w.End-w.Start)
+	fc := newFacetCounter(facets)
+	for i, h := range hits {
+		b, _ := cat.beer(h.Doc)
+		fc.add(b)
+		if i >= w.Start && i < w.End {
+			out = append(out, SearchHit{BeerResponse: b, Score: h.Score})
+		}
+	}
+
+	return SearchPage{
+		Hits:     out,
+		Total:    len(hits),
+		Offset:   w.Start,
+		Limit:    w.Limit,
+		Next:     w.Next,
+		Prev:     w.Prev,
+		Facets:   fc.result(),
+		Snapshot: cat.info(status),
+	}, nil
+}
@@ -0,0 +1,158 @@
+package beer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	backendbeer "interview-go/backend/client"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// maxSearchBody bounds the JSON body of POST /beer/search.
+const maxSearchBody = 64 << 10
+
+// SearchRequest is the JSON body of POST /beer/search: the upstream
+// BeerRequest extended with sort, pagination and projection.
+type SearchRequest struct {
+	backendbeer.BeerRequest
+
+	Sort   string   `json:"sort,omitempty" validate:"omitempty,beersort"` // e.g. "-abv,name"
+	Limit  int      `json:"limit,omitempty" validate:"gte=0,lte=500"`
+	Offset int      `json:"offset,omitempty" validate:"gte=0"`
+	Cursor string   `json:"cursor,omitempty"`
+	Fields []string `json:"fields,omitempty" validate:"max=50,dive,beerfield"`
+	Facets []string `json:"facets,omitempty" validate:"dive,oneof=food style decade abv_bucket"`
+}
+
+var requestValidator = newRequestValidator()
+
+func newRequestValidator() *validator.Validate {
+	v := validator.New()
+	// report JSON names, so errors point at the body the client sent
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	_ = v.RegisterValidation("beerfield", func(fl validator.FieldLevel) bool {
+		return validFieldPath(reflect.TypeOf(backendbeer.BeerResponse{}), strings.Split(fl.Field().String(), "."))
+	})
+	_ = v.RegisterValidation("beersort", func(fl validator.FieldLevel) bool {
+		keys, err := ParseSort(fl.Field().String())
+		if err != nil {
+			return false
+		}
+		ve := &ValidationError{}
+		validateSort(keys, ve)
+		return ve.orNil() == nil
+	})
+	return v
+}
+
+// DecodeSearchRequest reads and validates a search body. Every problem is
+// reported as a *ValidationError keyed by JSON field names.
+func DecodeSearchRequest(r io.Reader) (SearchRequest, error) {
+	var req SearchRequest
+
+	dec := json.NewDecoder(io.LimitReader(r, maxSearchBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		ve := &ValidationError{}
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr):
+			ve.add(typeErr.Field, "must be a %s", typeErr.Type)
+		case errors.Is(err, io.EOF):
+			ve.add("body", "a JSON object is required")
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			ve.add(strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`), "unknown field")
+		default:
+			ve.add("body", "%v", err)
+		}
+		return req, ve
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		ve := &ValidationError{}
+		ve.add("body", "must hold a single JSON object")
+		return req, ve
+	}
+
+	if err := requestValidator.Struct(req); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return req, err
+		}
+		ve := &ValidationError{}
+		for _, fe := range verrs {
+			ve.add(fe.Field(), "%s", validationMessage(fe))
+		}
+		return req, ve
+	}
+
+	return req, nil
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "datetime":
+		return fmt.Sprintf("must be a month in yyyy-mm format, got %q", fe.Value())
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return "must have at most " + fe.Param() + " items"
+		}
+		return "must be at most " + fe.Param() + " characters long"
+	case "beerfield":
+		return fmt.Sprintf("unknown field %q", fe.Value())
+	case "beersort":
+		return fmt.Sprintf("invalid sort %q", fe.Value())
+	default:
+		return fmt.Sprintf("failed the %s check", fe.Tag())
+	}
+}
+
+// Query turns the request into the BeerQuery run by GetFilteredBeers. No
+// default filters apply: an empty request matches every beer.
+func (r SearchRequest) Query() BeerQuery {
+	q := BeerQuery{
+		Filters: BeerFilter{
+			Name: r.BeerName,
+			Ingredients: IngredientFilter{
+				Hops:         r.Hops,
+				HopAttribute: r.HopAttribute,
+				Malt:         r.Malt,
+				Yeast:        r.Yeast,
+				MinHops:      r.MinHops,
+				MinMalt:      r.MinMalt,
+			},
+		},
+		Page:   Page{Limit: r.Limit, Offset: r.Offset, Cursor: r.Cursor},
+		Fields: r.Fields,
+		Facets: r.Facets,
+	}
+	if r.Food != "" {
+		q.Filters.Foods = []string{r.Food}
+	}
+	if r.BrewedAfter != "" {
+		q.Filters.Brewed.Lower = Bound[string]{Value: r.BrewedAfter, Set: true}
+	}
+	if r.BrewedBefore != "" {
+		q.Filters.Brewed.Upper = Bound[string]{Value: r.BrewedBefore, Set: true}
+	}
+	if r.Sort != "" {
+		// validated by the beersort tag
+		q.Sort, _ = ParseSort(r.Sort)
+	}
+	return q
+}
@@ -1,98 +1,304 @@
 package beer
 
 import (
+	"encoding/gob"
 	"errors"
-	"fmt"
 	backendbeer "interview-go/backend/client"
 	"interview-go/config"
 	"interview-go/internal/cache"
 	"log"
+	"slices"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 )
 
 type Service interface {
-	GetAllBeers() ([]backendbeer.BeerResponse, error)
-	GetFilteredBeers(filters string) ([]backendbeer.BeerResponse, error)
-	GetDefaultFilters() BeerFilter
+	GetAllBeers(page Page) (BeerPage, error)
+	GetFilteredBeers(q BeerQuery) (BeerPage, error)
+	GetDefaultQuery() BeerQuery
+	GetBeer(id int) (backendbeer.BeerResponse, error)
+	GetBeersByIDs(ids []int) ([]backendbeer.BeerResponse, error)
+	SearchBeers(text string, page Page, facets []string) (SearchPage, error)
+	GetStats(q BeerQuery, top int) (BeerStats, error)
+	GetSimilarBeers(id int, limit int) ([]SimilarBeer, error)
+	GetFoods() ([]ValueCount, error)
+	GetBeersByFood(food string, page Page) (BeerPage, error)
+	CompareBeers(ids []int) (BeerComparison, error)
+	Snapshot() (SnapshotInfo, error)
 }
 
 type service struct {
 	cache       cache.Cache
+	negativeTTL time.Duration
 	client      backendbeer.Client
 	rateLimiter *rate.Limiter // for api rate limit simulation
+	engine      Engine
+	similarity  SimilarityWeights
+
+	mu      sync.Mutex
+	current *catalog
 }
 
-type BeerFilter struct {
-	IncludeIpa   bool
-	Year         int
-	HasFood      string
-	AbvSortOrder string
+// negativeEntry is cached instead of a beer list when the upstream failed
+// or returned nothing, so repeated requests do not spend rate limit tokens.
+// It expires after its own, shorter TTL.
+type negativeEntry struct {
+	Message   string // upstream error message, empty for an empty result
+	ExpiresAt time.Time
+
+	err error // original error, only kept by in-memory backends
+}
+
+func (ne negativeEntry) replay() ([]backendbeer.BeerResponse, error) {
+	if ne.Message == "" {
+		return []backendbeer.BeerResponse{}, nil
+	}
+	if ne.err != nil {
+		return nil, ne.err
+	}
+	return nil, errors.New(ne.Message)
 }
 
 var (
 	ErrRateLimitExceeded = errors.New("api rate limit exceeded")
+	ErrBeerNotFound      = errors.New("beer not found")
+	ErrFoodNotFound      = errors.New("food not found")
 )
 
-func NewService(client backendbeer.Client, cfg *config.Configuration) Service {
+func init() {
+	// values stored in serializing cache backends (disk, redis)
+	gob.Register([]backendbeer.BeerResponse{})
+	gob.Register(encodedResponse{})
+	gob.Register(negativeEntry{})
+	gob.Register(catalogData{})
+}
+
+func NewService(client backendbeer.Client, c cache.Cache, cfg *config.Configuration) Service {
 	return &service{
-		cache:       cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker),
+		cache:       c,
+		negativeTTL: cfg.Cache.NegativeTTL,
 		client:      client,
 		rateLimiter: rate.NewLimiter(rate.Every(cfg.ApiRateLimit.Rate), cfg.ApiRateLimit.Burst),
+		engine:      Engine{Synonyms: NewSynonyms(cfg.Food.Synonyms)},
+		similarity:  similarityWeights(cfg),
 	}
 }
 
-func (s *service) GetAllBeers() ([]backendbeer.BeerResponse, error) {
-	return s.client.ListBeers()
+func (s *service) GetAllBeers(page Page) (BeerPage, error) {
+	if err := page.Validate(); err != nil {
+		return BeerPage{}, err
+	}
+
+	cat, status, err := s.load()
+	if err != nil {
+		return BeerPage{}, err
+	}
+
+	p, err := paginate(cat.Beers, page, cat.Version, "all")
+	if err != nil {
+		return BeerPage{}, err
+	}
+	p.Snapshot = cat.info(status)
+	return p, nil
 }
 
-func (s *service) GetFilteredBeers(filters string) ([]backendbeer.BeerResponse, error) {
-	if filters != "" {
-		cachedValue, err := s.cache.Get(filters)
-		if err != nil {
-			if err != cache.ErrCacheMiss && err != cache.ErrTTLExpired {
-				return nil, err
+// GetFilteredBeers runs the query over the catalog snapshot and returns
+// the requested page. Results are cached per snapshot version; invalid
+// queries are rejected before any cache or upstream access.
+func (s *service) GetFilteredBeers(q BeerQuery) (BeerPage, error) {
+	if err := q.Validate(); err != nil {
+		return BeerPage{}, err
+	}
+
+	cat, status, err := s.load()
+	if err != nil {
+		return BeerPage{}, err
+	}
+
+	fc := newFacetCounter(q.Facets)
+	var visit func(backendbeer.BeerResponse)
+	if len(q.Facets) > 0 {
+		visit = fc.add
+	}
+	filtered, err := s.filtered(cat, q, visit)
+	if err != nil {
+		return BeerPage{}, err
+	}
+
+	page, err := paginate(filtered, q.Page, cat.Version, "filtered:"+q.resultKey())
+	if err != nil {
+		return BeerPage{}, err
+	}
+	page.Facets = fc.result()
+	page.Snapshot = cat.info(status)
+	return page, nil
+}
+
+// GetStats aggregates the beers matching the filters of q; sort and page
+// are ignored. top limits the top food, hop and malt lists.
+func (s *service) GetStats(q BeerQuery, top int) (BeerStats, error) {
+	if err := q.Validate(); err != nil {
+		return BeerStats{}, err
+	}
+
+	cat, err := s.snapshot()
+	if err != nil {
+		return BeerStats{}, err
+	}
+
+	filtered, err := s.filtered(cat, q, nil)
+	if err != nil {
+		return BeerStats{}, err
+	}
+
+	return computeStats(filtered, top), nil
+}
+
+// filtered runs the query over the snapshot. Results are cached per
+// snapshot version. visit, when set, sees every match once: during the
+// filter pass, or while walking a cached result that needs no filtering.
+func (s *service) filtered(cat *catalog, q BeerQuery, visit func(backendbeer.BeerResponse)) ([]backendbeer.BeerResponse, error) {
+	key := "filtered:" + cat.Version + "|" + q.resultKey()
+	filtered, ok, err := s.cached(key)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if visit != nil {
+			for _, b := range filtered {
+				visit(b)
 			}
 		}
-		if cachedValue != nil {
-			beers, ok := cachedValue.([]backendbeer.BeerResponse)
-			if !ok {
-				return nil, errors.New("malformed data type in cache")
-			}
-			return beers, nil
+		return filtered, nil
+	}
+
+	compiled := s.engine.forCatalog(cat).Compile(q)
+	compiled.Visit = visit
+	filtered = compiled.Run(s.candidates(cat, q.Filters))
+	if err := s.cache.Set(key, filtered); err != nil {
+		// do not return here, just log it
+		log.Println(err)
+	}
+	return filtered, nil
+}
+
+// candidates narrows the snapshot with the upstream filter when the client
+// supports one. The upstream may ignore criteria, so its result only picks
+// snapshot beers by ID and the compiled query still decides. Without a
+// rate limit token or when the upstream fails the whole snapshot is used.
+func (s *service) candidates(cat *catalog, bf BeerFilter) []backendbeer.BeerResponse {
+	fc, ok := s.client.(backendbeer.FilteringClient)
+	req := bf.Request()
+	if !ok || req == (backendbeer.BeerRequest{}) || !s.rateLimiter.Allow() {
+		return cat.Beers
+	}
+
+	matches, err := fc.FilterBeers(req)
+	if err != nil {
+		// do not return here, the snapshot still answers the query
+		log.Println(err)
+		return cat.Beers
+	}
+
+	positions := make([]int, 0, len(matches))
+	for _, b := range matches {
+		if i, ok := cat.byID[b.ID]; ok {
+			positions = append(positions, i)
 		}
 	}
+	slices.Sort(positions)
+	positions = slices.Compact(positions)
+
+	beers := make([]backendbeer.BeerResponse, len(positions))
+	for i, p := range positions {
+		beers[i] = cat.Beers[p]
+	}
+	return beers
+}
+
+func (s *service) GetBeer(id int) (backendbeer.BeerResponse, error) {
+	cat, err := s.snapshot()
+	if err != nil {
+		return backendbeer.BeerResponse{}, err
+	}
 
-	// simulating api rate limit
-	if !s.rateLimiter.Allow() {
-		return nil, ErrRateLimitExceeded
+	b, ok := cat.beer(id)
+	if !ok {
+		return backendbeer.BeerResponse{}, ErrBeerNotFound
 	}
+	return b, nil
+}
 
-	beers, err := s.client.ListBeers()
+// GetBeersByIDs returns the beers with the given IDs in request order.
+// Unknown and repeated IDs are skipped.
+func (s *service) GetBeersByIDs(ids []int) ([]backendbeer.BeerResponse, error) {
+	cat, err := s.snapshot()
 	if err != nil {
 		return nil, err
 	}
 
-	err = s.cache.Set(filters, beers)
+	seen := make(map[int]bool, len(ids))
+	out := make([]backendbeer.BeerResponse, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if b, ok := cat.beer(id); ok {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// cached looks up a filtered beer list stored under key. Negative entries
+// are only written for the catalog, so they never show up here.
+// ok is false when the caller has to compute the value itself.
+func (s *service) cached(key string) (beers []backendbeer.BeerResponse, ok bool, err error) {
+	cachedValue, err := s.cache.Get(key)
 	if err != nil {
-		// do not return here, just log it
-		log.Println(err)
+		if err != cache.ErrCacheMiss && err != cache.ErrTTLExpired {
+			return nil, false, err
+		}
 	}
 
-	return beers, nil
+	switch v := cachedValue.(type) {
+	case nil:
+	case []backendbeer.BeerResponse:
+		return v, true, nil
+	default:
+		return nil, false, errors.New("malformed data type in cache")
+	}
+	return nil, false, nil
 }
 
-func (s *service) GetDefaultFilters() BeerFilter {
-	filter := BeerFilter{
-		IncludeIpa:   true,
-		Year:         2015,
-		HasFood:      "wolf",
-		AbvSortOrder: "asc",
+func (s *service) setNegative(key string, upstreamErr error) {
+	if s.negativeTTL <= 0 {
+		return
 	}
 
-	return filter
+	ne := negativeEntry{
+		ExpiresAt: time.Now().Add(s.negativeTTL),
+		err:       upstreamErr,
+	}
+	if upstreamErr != nil {
+		ne.Message = upstreamErr.Error()
+	}
+
+	if err := s.cache.Set(key, ne); err != nil {
+		log.Println(err)
+	}
 }
 
-func (bf *BeerFilter) String() string {
-	return fmt.Sprintf("%t%d%s%s", bf.IncludeIpa, bf.Year, bf.HasFood, bf.AbvSortOrder)
+func (s *service) GetDefaultQuery() BeerQuery {
+	return BeerQuery{
+		Filters: BeerFilter{
+			Styles: []Style{StyleIPA},
+			Year:   2015,
+			Foods:  []string{"wolf"},
+		},
+		Sort: []SortKey{{Field: "abv"}},
+	}
 }
@@ -0,0 +1,148 @@
+package beer
+
+import (
+	"cmp"
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/search"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultSimilarLimit = 10
+	MaxSimilarLimit     = 100
+
+	// abvScale is the ABV distance at which beers stop being similar.
+	abvScale = 5.0
+)
+
+// SimilarityWeights weighs the features compared by GetSimilarBeers.
+type SimilarityWeights struct {
+	Hops  float64
+	Malts float64
+	ABV   float64
+	Style float64
+	Food  float64
+}
+
+func similarityWeights(cfg *config.Configuration) SimilarityWeights {
+	s := cfg.Similarity
+	return SimilarityWeights{Hops: s.Hops, Malts: s.Malts, ABV: s.ABV, Style: s.Style, Food: s.Food}
+}
+
+func (w SimilarityWeights) total() float64 {
+	return w.Hops + w.Malts + w.ABV + w.Style + w.Food
+}
+
+// SimilarBeer is a recommended beer with its similarity in [0, 1].
+type SimilarBeer struct {
+	backendbeer.BeerResponse
+	Score float64 `json:"score"`
+}
+
+// features is the precomputed feature vector of one beer. Sets are sorted
+// so they intersect in one merge.
+type features struct {
+	hops  []string
+	malts []string
+	food  []string // stemmed words of the food pairings
+	abv   float64
+	style Style
+}
+
+func newFeatures(b backendbeer.BeerResponse) features {
+	f := features{abv: b.ABV, style: Classify(b)}
+	for _, h := range b.Ingredients.Hops {
+		f.hops = append(f.hops, strings.ToLower(strings.TrimSpace(h.Name)))
+	}
+	for _, m := range b.Ingredients.Malt {
+		f.malts = append(f.malts, strings.ToLower(strings.TrimSpace(m.Name)))
+	}
+	for _, fp := range b.FoodPairing {
+		f.food = append(f.food, search.Tokenize(fp)...)
+	}
+	f.hops, f.malts, f.food = sortedSet(f.hops), sortedSet(f.malts), sortedSet(f.food)
+	return f
+}
+
+func sortedSet(s []string) []string {
+	slices.Sort(s)
+	return slices.Compact(s)
+}
+
+// jaccard is |a ∩ b| / |a ∪ b| of two sorted sets; 0 when both are empty.
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared, i, j := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		switch c := strings.Compare(a[i], b[j]); {
+		case c == 0:
+			shared++
+			i++
+			j++
+		case c < 0:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// similarity combines the weighted feature similarities into [0, 1].
+func (w SimilarityWeights) similarity(a, b features) float64 {
+	total := w.total()
+	if total == 0 {
+		return 0
+	}
+
+	score := w.Hops*jaccard(a.hops, b.hops) +
+		w.Malts*jaccard(a.malts, b.malts) +
+		w.ABV*math.Max(0, 1-math.Abs(a.abv-b.abv)/abvScale) +
+		w.Food*jaccard(a.food, b.food)
+	if a.style == b.style && a.style != StyleOther {
+		score += w.Style
+	}
+	return score / total
+}
+
+// GetSimilarBeers ranks the other beers of the snapshot by similarity to
+// the beer with id, best first, ties by ID. Beers sharing nothing with it
+// are left out; limit bounds the result.
+func (s *service) GetSimilarBeers(id int, limit int) ([]SimilarBeer, error) {
+	cat, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := cat.byID[id]
+	if !ok {
+		return nil, ErrBeerNotFound
+	}
+	target := cat.features[i]
+
+	ranked := make([]SimilarBeer, 0, len(cat.Beers))
+	for j, b := range cat.Beers {
+		if j == i {
+			continue
+		}
+		if score := s.similarity.similarity(target, cat.features[j]); score > 0 {
+			ranked = append(ranked, SimilarBeer{BeerResponse: b, Score: math.Round(score*1000) / 1000})
+		}
+	}
+	slices.SortFunc(ranked, func(a, b SimilarBeer) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
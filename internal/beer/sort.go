@@ -0,0 +1,121 @@
+package beer
+
+import (
+	"cmp"
+	backendbeer "interview-go/backend/client"
+	"strings"
+)
+
+// SortKey orders by one BeerResponse field, using its JSON name.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// sortComparators are the fields a query can be sorted by.
+var sortComparators = map[string]Comparator{
+	"id":             ByID,
+	"name":           byText(func(b backendbeer.BeerResponse) string { return b.Name }),
+	"tagline":        byText(func(b backendbeer.BeerResponse) string { return b.Tagline }),
+	"first_brewed":   ByFirstBrewed,
+	"description":    byText(func(b backendbeer.BeerResponse) string { return b.Description }),
+	"abv":            ByABV,
+	"ibu":            ByIBU,
+	"brewers_tips":   byText(func(b backendbeer.BeerResponse) string { return b.BrewersTips }),
+	"contributed_by": byText(func(b backendbeer.BeerResponse) string { return b.ContributedBy }),
+}
+
+func ByID(a, b backendbeer.BeerResponse) int {
+	return cmp.Compare(a.ID, b.ID)
+}
+
+func ByABV(a, b backendbeer.BeerResponse) int {
+	return cmp.Compare(a.ABV, b.ABV)
+}
+
+func ByIBU(a, b backendbeer.BeerResponse) int {
+	return cmp.Compare(a.IBU, b.IBU)
+}
+
+// ByFirstBrewed compares "YYYY-MM" dates, which sort lexically.
+func ByFirstBrewed(a, b backendbeer.BeerResponse) int {
+	return strings.Compare(a.FirstBrewed, b.FirstBrewed)
+}
+
+// byText compares a text field ignoring case.
+func byText(field func(backendbeer.BeerResponse) string) Comparator {
+	return func(a, b backendbeer.BeerResponse) int {
+		return strings.Compare(strings.ToLower(field(a)), strings.ToLower(field(b)))
+	}
+}
+
+// ParseSort parses a sort expression like "-abv,name,id", where a leading
+// "-" sorts descending. Field names are checked by BeerQuery.Validate.
+func ParseSort(s string) ([]SortKey, error) {
+	ve := &ValidationError{}
+	var keys []SortKey
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		sk := SortKey{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if sk.Field == "" {
+			ve.add("sort", "empty sort field in %q", s)
+			continue
+		}
+		keys = append(keys, sk)
+	}
+	return keys, ve.orNil()
+}
+
+// FormatSort is the inverse of ParseSort.
+func FormatSort(keys []SortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, sk := range keys {
+		if sk.Desc {
+			parts = append(parts, "-"+sk.Field)
+		} else {
+			parts = append(parts, sk.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func validateSort(keys []SortKey, ve *ValidationError) {
+	seen := make(map[string]bool, len(keys))
+	for _, sk := range keys {
+		if _, ok := sortComparators[sk.Field]; !ok {
+			ve.add("sort", "unknown sort field %q", sk.Field)
+			continue
+		}
+		if seen[sk.Field] {
+			ve.add("sort", "duplicate sort field %q", sk.Field)
+		}
+		seen[sk.Field] = true
+	}
+}
+
+// sortComparator chains the keys into one comparator. Ties left by the
+// keys are broken by ID so equal values always come out in the same order.
+func sortComparator(keys []SortKey) Comparator {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var out Comparator
+	byID := false
+	for _, sk := range keys {
+		c := sortComparators[sk.Field]
+		if sk.Desc {
+			c = c.Reverse()
+		}
+		if out == nil {
+			out = c
+		} else {
+			out = out.Then(c)
+		}
+		byID = byID || sk.Field == "id"
+	}
+	if !byID {
+		out = out.Then(ByID)
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package beer
+
+import (
+	"cmp"
+	"fmt"
+	backendbeer "interview-go/backend/client"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultStatsTop = 10
+	MaxStatsTop     = 100
+)
+
+// statsPercentiles are reported for every numeric distribution.
+var statsPercentiles = []float64{25, 50, 75, 90, 95, 99}
+
+// NumberStats summarizes a numeric field.
+type NumberStats struct {
+	Count       int                `json:"count"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Mean        float64            `json:"mean"`
+	Percentiles map[string]float64 `json:"percentiles,omitempty"` // "p50" -> value
+}
+
+// ValueCount is one entry of a top list.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// BeerStats aggregates the beers matching a query.
+type BeerStats struct {
+	Count    int            `json:"count"`
+	ABV      NumberStats    `json:"abv"`
+	ByYear   map[int]int    `json:"by_year"`   // first brewing year -> beers
+	ByDecade map[string]int `json:"by_decade"` // "1990s" -> beers
+	TopFoods []ValueCount   `json:"top_foods"`
+	TopHops  []ValueCount   `json:"top_hops"`
+	TopMalts []ValueCount   `json:"top_malts"`
+}
+
+// computeStats aggregates beers; top limits each top list.
+func computeStats(beers []backendbeer.BeerResponse, top int) BeerStats {
+	st := BeerStats{
+		Count:    len(beers),
+		ByYear:   map[int]int{},
+		ByDecade: map[string]int{},
+	}
+
+	abv := make([]float64, 0, len(beers))
+	foods := newCounter()
+	hops := newCounter()
+	malts := newCounter()
+	for _, b := range beers {
+		abv = append(abv, b.ABV)
+
+		if year := extractYear(b.FirstBrewed); year > 0 {
+			st.ByYear[year]++
+			st.ByDecade[fmt.Sprintf("%ds", year/10*10)]++
+		}
+
+		// every list counts beers, not repeated mentions within a beer
+		perBeer := map[string]bool{}
+		for _, f := range b.FoodPairing {
+			foods.addOnce(perBeer, "food:", f)
+		}
+		for _, h := range b.Ingredients.Hops {
+			hops.addOnce(perBeer, "hop:", h.Name)
+		}
+		for _, m := range b.Ingredients.Malt {
+			malts.addOnce(perBeer, "malt:", m.Name)
+		}
+	}
+
+	st.ABV = numberStats(abv)
+	st.TopFoods = foods.top(top)
+	st.TopHops = hops.top(top)
+	st.TopMalts = malts.top(top)
+	return st
+}
+
+func numberStats(values []float64) NumberStats {
+	if len(values) == 0 {
+		return NumberStats{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+
+	ns := NumberStats{
+		Count:       len(sorted),
+		Min:         sorted[0],
+		Max:         sorted[len(sorted)-1],
+		Mean:        round2(sum / float64(len(sorted))),
+		Percentiles: make(map[string]float64, len(statsPercentiles)),
+	}
+	for _, p := range statsPercentiles {
+		ns.Percentiles[fmt.Sprintf("p%g", p)] = round2(percentile(sorted, p))
+	}
+	return ns
+}
+
+// percentile interpolates linearly between the closest ranks of sorted.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lo, hi := int(math.Floor(rank)), int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// counter counts values case-insensitively and reports the spelling seen
+// first.
+type counter struct {
+	counts   map[string]int
+	spelling map[string]string
+}
+
+func newCounter() *counter {
+	return &counter{counts: map[string]int{}, spelling: map[string]string{}}
+}
+
+func (c *counter) addOnce(seen map[string]bool, kind, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	key := strings.ToLower(value)
+	if seen[kind+key] {
+		return
+	}
+	seen[kind+key] = true
+
+	if _, ok := c.spelling[key]; !ok {
+		c.spelling[key] = value
+	}
+	c.counts[key]++
+}
+
+// top returns the n most frequent values, ties in alphabetical order.
+func (c *counter) top(n int) []ValueCount {
+	out := make([]ValueCount, 0, len(c.counts))
+	for key, count := range c.counts {
+		out = append(out, ValueCount{Value: c.spelling[key], Count: count})
+	}
+	slices.SortFunc(out, func(a, b ValueCount) int {
+		if r := cmp.Compare(b.Count, a.Count); r != 0 {
+			return r
+		}
+		return cmp.Compare(strings.ToLower(a.Value), strings.ToLower(b.Value))
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
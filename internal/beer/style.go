@@ -0,0 +1,178 @@
+package beer
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/search"
+	"strings"
+)
+
+// Style is a family of the beer style taxonomy, identified by its slug.
+type Style string
+
+const (
+	StyleIPA       Style = "ipa"
+	StylePaleAle   Style = "pale-ale"
+	StyleAmber     Style = "amber-brown"
+	StyleStout     Style = "stout"
+	StylePorter    Style = "porter"
+	StyleLager     Style = "lager"
+	StyleWheat     Style = "wheat"
+	StyleBelgian   Style = "belgian"
+	StyleSour      Style = "sour"
+	StyleStrongAle Style = "strong-ale"
+	StyleSpecialty Style = "specialty"
+	StyleOther     Style = "other"
+)
+
+// styleRule describes how a style is recognized: by keyword phrases in the
+// tagline or name and by its typical ABV and IBU.
+type styleRule struct {
+	style    Style
+	keywords []string
+	abv      [2]float64
+	ibu      [2]float64
+}
+
+// styleRules are ordered from specific to generic; on equal scores the
+// earlier rule wins, so "Imperial IPA" is an IPA and not a strong ale.
+var styleRules = []styleRule{
+	{StyleIPA, []string{"ipa", "india pale ale", "double ipa", "imperial ipa", "neipa", "hazy ipa", "west coast ipa"}, [2]float64{5.5, 10}, [2]float64{40, 120}},
+	{StyleSour, []string{"sour", "gose", "lambic", "gueuze", "berliner weisse", "flanders red", "wild ale"}, [2]float64{3, 8}, [2]float64{0, 15}},
+	{StyleBelgian, []string{"belgian", "belgian strong", "saison", "farmhouse", "tripel", "dubbel", "quadrupel", "trappist", "abbey", "french ale", "biere de garde"}, [2]float64{5, 11}, [2]float64{15, 40}},
+	{StyleStout, []string{"stout", "imperial stout", "russian imperial stout", "oatmeal stout", "milk stout"}, [2]float64{4, 12}, [2]float64{25, 80}},
+	{StylePorter, []string{"porter", "baltic porter"}, [2]float64{4.5, 7.5}, [2]float64{20, 50}},
+	{StyleWheat, []string{"wheat", "weizen", "hefeweizen", "weissbier", "hefeweissbier", "witbier", "wit", "rye beer", "dunkelweizen"}, [2]float64{4, 6}, [2]float64{8, 25}},
+	{StyleLager, []string{"lager", "pilsner", "pils", "pilsener", "helles", "bock", "doppelbock", "eisbock", "maibock", "marzen", "oktoberfest", "dunkel", "schwarzbier", "vienna lager"}, [2]float64{3.5, 7.5}, [2]float64{8, 35}},
+	{StyleStrongAle, []string{"strong ale", "barleywine", "barley wine", "old ale", "scotch ale", "wee heavy", "english strong"}, [2]float64{7, 14}, [2]float64{30, 100}},
+	{StyleAmber, []string{"amber", "amber ale", "brown ale", "red ale", "irish", "scottish", "esb", "mild", "altbier", "amber hybrid", "california common"}, [2]float64{4, 6.5}, [2]float64{15, 40}},
+	{StylePaleAle, []string{"pale ale", "apa", "american ale", "merican ale", "blonde", "golden ale", "kolsch", "cream ale", "light hybrid", "bitter"}, [2]float64{4, 6.5}, [2]float64{20, 50}},
+	{StyleSpecialty, []string{"fruit beer", "vegetable beer", "smoke", "smoked", "rauchbier", "wood aged", "barrel aged", "spiced", "pumpkin"}, [2]float64{}, [2]float64{}},
+}
+
+// Styles lists every style of the taxonomy.
+func Styles() []Style {
+	out := make([]Style, 0, len(styleRules)+1)
+	for _, r := range styleRules {
+		out = append(out, r.style)
+	}
+	return append(out, StyleOther)
+}
+
+func knownStyle(s Style) bool {
+	for _, st := range Styles() {
+		if st == s {
+			return true
+		}
+	}
+	return false
+}
+
+// phrase reports whether words contains the words of p in sequence.
+func phrase(words []string, p string) bool {
+	return strings.Contains(" "+strings.Join(words, " ")+" ", " "+p+" ")
+}
+
+// longestPhrase returns the word count of the longest keyword in words, so
+// "india pale ale" outweighs "pale ale".
+func longestPhrase(words []string, keywords []string) int {
+	n := 0
+	for _, kw := range keywords {
+		if phrase(words, kw) {
+			n = max(n, len(strings.Fields(kw)))
+		}
+	}
+	return n
+}
+
+// fits reports whether the ABV and IBU are both inside the typical ranges.
+func (r styleRule) fits(abv, ibu float64) bool {
+	if r.ibu[1] == 0 || abv <= 0 || ibu <= 0 {
+		return false
+	}
+	return abv >= r.abv[0] && abv <= r.abv[1] && ibu >= r.ibu[0] && ibu <= r.ibu[1]
+}
+
+// Classify maps a beer to its style. The tagline holds the declared style
+// and weighs more than the name; ABV and IBU add a point when they fit a
+// style. Without any keyword the numbers decide only when exactly one
+// style fits them, anything else is StyleOther.
+func Classify(b backendbeer.BeerResponse) Style {
+	tagline, name := search.Words(foldAccents(b.Tagline)), search.Words(foldAccents(b.Name))
+
+	best, bestScore, fitting := StyleOther, 0, 0
+	for _, r := range styleRules {
+		score := 3*longestPhrase(tagline, r.keywords) + 2*longestPhrase(name, r.keywords)
+
+		fit := r.fits(b.ABV, b.IBU)
+		if fit {
+			fitting++
+		}
+		if score == 0 {
+			continue
+		}
+		if fit {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = r.style, score
+		}
+	}
+	if bestScore > 0 {
+		return best
+	}
+
+	if fitting == 1 {
+		for _, r := range styleRules {
+			if r.fits(b.ABV, b.IBU) {
+				return r.style
+			}
+		}
+	}
+	return StyleOther
+}
+
+var accentFolder = strings.NewReplacer("ä", "a", "ö", "o", "ü", "u", "é", "e", "è", "e", "ß", "ss")
+
+func foldAccents(s string) string {
+	return accentFolder.Replace(strings.ToLower(s))
+}
+
+// HasStyle matches beers classified as one of styles.
+func HasStyle(styles ...Style) Predicate {
+	return func(b backendbeer.BeerResponse) bool {
+		style := Classify(b)
+		for _, s := range styles {
+			if s == style {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ParseStyles reads a comma separated list of style slugs.
+func ParseStyles(s string) []Style {
+	var out []Style
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			out = append(out, Style(part))
+		}
+	}
+	return out
+}
+
+func validateStyles(styles []Style, ve *ValidationError) {
+	for _, s := range styles {
+		if !knownStyle(s) {
+			ve.add("style", "unknown style %q, expected one of %s", s, joinStyles(Styles()))
+		}
+	}
+}
+
+func joinStyles(styles []Style) string {
+	parts := make([]string, len(styles))
+	for i, s := range styles {
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"encoding/json"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareBeers_Overlap(t *testing.T) {
+	client := catalogClient(compareCatalog())
+	svc := newTestService(client, newTestConfig())
+
+	got, err := svc.CompareBeers([]int{42, 1, 7, 1})
+	require.NoError(t, err)
+
+	// requested order, repeated IDs once
+	require.Len(t, got.Beers, 3)
+	require.Equal(t, 42, got.Beers[0].ID)
+	require.Equal(t, beer.StyleStout, got.Beers[0].Style)
+
+	punk := got.Beers[1]
+	require.Equal(t, beer.StyleIPA, punk.Style)
+	require.Equal(t, "2007-04", punk.FirstBrewed)
+	// additions of the same hop are summed
+	require.Equal(t, []beer.IngredientAmount{{Name: "Citra", Grams: 25}, {Name: "Simcoe", Grams: 5}}, punk.Hops)
+	require.Equal(t, []beer.IngredientAmount{{Name: "Extra Pale", Grams: 5300}}, punk.Malts)
+
+	// shared keeps the spelling of the first beer; values of two out of
+	// three beers are neither shared nor unique
+	require.Equal(t, beer.Overlap{
+		Shared: []string{"Citra"},
+		Unique: map[int][]string{42: {}, 1: {}, 7: {"Mosaic"}},
+	}, got.Hops)
+	require.Equal(t, beer.Overlap{
+		Shared: []string{"Extra Pale"},
+		Unique: map[int][]string{42: {"Chocolate"}, 1: {}, 7: {"Caramalt"}},
+	}, got.Malts)
+	require.Equal(t, beer.Overlap{
+		Shared: []string{},
+		Unique: map[int][]string{42: {"Wyeast 1272"}, 1: {}, 7: {}},
+	}, got.Yeast)
+	require.Equal(t, beer.Overlap{
+		Shared: []string{"Spicy chicken"},
+		Unique: map[int][]string{42: {}, 1: {"Cheesecake"}, 7: {}},
+	}, got.FoodPairing)
+}
+
+func TestCompareBeers_Errors(t *testing.T) {
+	client := catalogClient(compareCatalog())
+	svc := newTestService(client, newTestConfig())
+
+	var ve *beer.ValidationError
+	_, err := svc.CompareBeers([]int{1, 1})
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, "ids", ve.Errors[0].Field)
+
+	_, err = svc.CompareBeers([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+	require.ErrorAs(t, err, &ve)
+
+	_, err = svc.CompareBeers([]int{1, 99})
+	require.ErrorIs(t, err, beer.ErrBeerNotFound)
+
+	// validation happens before the upstream is called
+	require.Equal(t, 1, client.Calls)
+}
+
+func TestCompareBeers_Handler(t *testing.T) {
+	e := setupEcho()
+	var gotIDs []int
+	svc := &mockService{
+		CompareBeersFunc: func(ids []int) (beer.BeerComparison, error) {
+			gotIDs = ids
+			if ids[0] == 5 {
+				return beer.BeerComparison{}, beer.ErrBeerNotFound
+			}
+			return beer.BeerComparison{
+				Beers: []beer.ComparedBeer{{ID: 1, Name: "A"}, {ID: 7, Name: "B"}},
+				Hops:  beer.Overlap{Shared: []string{"Citra"}, Unique: map[int][]string{1: {}, 7: {"Mosaic"}}},
+			}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/beer/compare?ids=1,%207", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.CompareBeers(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []int{1, 7}, gotIDs)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, map[string]any{"shared": []any{"Citra"}, "unique": map[string]any{"1": []any{}, "7": []any{"Mosaic"}}}, got["hops"])
+
+	req = httptest.NewRequest(http.MethodGet, "/beer/compare?ids=1,x", nil)
+	var he *echo.HTTPError
+	require.ErrorAs(t, h.CompareBeers(e.NewContext(req, httptest.NewRecorder())), &he)
+	require.Equal(t, http.StatusBadRequest, he.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/beer/compare?ids=5,6", nil)
+	require.ErrorAs(t, h.CompareBeers(e.NewContext(req, httptest.NewRecorder())), &he)
+	require.Equal(t, http.StatusNotFound, he.Code)
+
+	// fewer than two distinct beers is rejected before the service is called
+	gotIDs = nil
+	req = httptest.NewRequest(http.MethodGet, "/beer/compare?ids=5,5", nil)
+	require.ErrorAs(t, h.CompareBeers(e.NewContext(req, httptest.NewRecorder())), &he)
+	require.Equal(t, http.StatusBadRequest, he.Code)
+	require.Nil(t, gotIDs)
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"encoding/json"
+	"interview-go/internal/beer"
+	"interview-go/internal/cache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAllBeers_SnapshotCacheStatus(t *testing.T) {
+	client := catalogClient(catalogOf(3))
+	cfg := newTestConfig()
+	cfg.Cache.TTL = 10 * time.Millisecond
+	cfg.ApiRateLimit.Burst = 1
+	svc := newTestService(client, cfg)
+
+	page, err := svc.GetAllBeers(beer.Page{})
+	require.NoError(t, err)
+	require.Equal(t, beer.CacheMiss, page.Snapshot.Cache)
+	require.NotEmpty(t, page.Snapshot.Version)
+	loaded := page.Snapshot.LoadedAt
+
+	page, err = svc.GetAllBeers(beer.Page{})
+	require.NoError(t, err)
+	require.Equal(t, beer.CacheHit, page.Snapshot.Cache)
+
+	// expired and rate limited: the previous snapshot is served as stale
+	time.Sleep(20 * time.Millisecond)
+	page, err = svc.GetAllBeers(beer.Page{})
+	require.NoError(t, err)
+	require.Equal(t, beer.CacheStale, page.Snapshot.Cache)
+	require.Equal(t, loaded, page.Snapshot.LoadedAt)
+	require.Equal(t, []int{1, 2, 3}, ids(page.Beers))
+	require.Equal(t, 1, client.Calls)
+}
+
+func TestGetAllBeers_RateLimitedWithoutSnapshot(t *testing.T) {
+	client := catalogClient(catalogOf(3))
+	cfg := newTestConfig()
+	cfg.ApiRateLimit.Burst = 0
+	svc := newTestService(client, cfg)
+
+	_, err := svc.GetAllBeers(beer.Page{})
+	require.ErrorIs(t, err, beer.ErrRateLimitExceeded)
+}
+
+func TestBeerQuery_Params(t *testing.T) {
+	q := beer.BeerQuery{
+		Filters: beer.BeerFilter{
+			Name:      "punk",
+			Styles:    []beer.Style{beer.StyleIPA, beer.StyleStout},
+			Year:      2015,
+			Foods:     []string{"wolf", "lamb"},
+			FoodMatch: beer.MatchAll,
+			ABV: beer.Range[float64]{
+				Lower: beer.Bound[float64]{Value: 4.5, Inclusive: true, Set: true},
+				Upper: beer.Bound[float64]{Value: 7, Set: true},
+			},
+			Brewed:      beer.Range[string]{Lower: beer.Bound[string]{Value: "2010-01", Set: true}},
+			Ingredients: beer.IngredientFilter{Hops: "Citra", MinHops: 20},
+			Expr:        beer.NewFilterExpr(`ibu > 40`),
+		},
+		Sort: []beer.SortKey{{Field: "abv", Desc: true}, {Field: "name"}},
+	}
+	require.Equal(t, map[string]string{
+		"name":         "punk",
+		"style":        "ipa,stout",
+		"year":         "2015",
+		"hasFood":      "wolf,lamb",
+		"match":        "all",
+		"abv_gte":      "4.5",
+		"abv_lt":       "7",
+		"brewed_after": "2010-01",
+		"hops":         "Citra",
+		"hops_min":     "20g",
+		"q":            "ibu > 40",
+		"sort":         "-abv,name",
+	}, q.Params())
+
+	require.Equal(t, map[string]string{}, beer.BeerQuery{}.Params())
+}
+
+func TestFilteredBeers_Envelope(t *testing.T) {
+	e := setupEcho()
+	loaded := time.Now().Add(-time.Minute)
+	empty := false
+	svc := &mockService{
+		GetDefaultQueryFunc: func() beer.BeerQuery {
+			return beer.BeerQuery{Filters: beer.BeerFilter{Year: 2015, Foods: []string{"wolf"}}, Sort: []beer.SortKey{{Field: "abv"}}}
+		},
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			page := beer.BeerPage{
+				Beers: catalogOf(2), Total: 5, Limit: 2, Next: "n",
+				Snapshot: beer.SnapshotInfo{Version: "v1", LoadedAt: loaded, Cache: beer.CacheHit},
+			}
+			if empty {
+				page = beer.BeerPage{Snapshot: page.Snapshot}
+			}
+			return page, nil
+		},
+	}
+	// a response cache must not serve envelopes
+	cfg := newTestConfig()
+	h := beer.NewHandler(svc, cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker), cfg)
+
+	get := func(target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.FilteredBeers(e.NewContext(req, rec)))
+		return rec
+	}
+
+	// old clients keep the bare array
+	rec := get("/beer/getFiltered?limit=2&fields=id", "")
+	require.JSONEq(t, `[{"id":1},{"id":2}]`, rec.Body.String())
+	require.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+	// the cached bare body tells caches it depends on Accept too
+	rec = get("/beer/getFiltered?limit=2&fields=id", "")
+	require.JSONEq(t, `[{"id":1},{"id":2}]`, rec.Body.String())
+	require.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+	for _, rec := range []*httptest.ResponseRecorder{
+		get("/beer/getFiltered?limit=2&fields=id&envelope=v1", ""),
+		get("/beer/getFiltered?limit=2&fields=id", beer.MediaTypeEnvelopeV1),
+	} {
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, beer.MediaTypeEnvelopeV1, rec.Header().Get("Content-Type"))
+		require.Equal(t, "Accept", rec.Header().Get("Vary"))
+		require.Empty(t, rec.Header().Get("ETag"))
+
+		var env struct {
+			Data  []map[string]any   `json:"data"`
+			Meta  beer.EnvelopeMeta  `json:"meta"`
+			Links beer.EnvelopeLinks `json:"links"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+		require.Equal(t, []map[string]any{{"id": 1.0}, {"id": 2.0}}, env.Data)
+		require.Equal(t, map[string]string{"year": "2015", "hasFood": "wolf", "sort": "abv"}, env.Meta.Filters)
+		require.Equal(t, 5, env.Meta.Total)
+		require.Equal(t, 2, env.Meta.Count)
+		require.Equal(t, 2, env.Meta.Limit)
+		require.Equal(t, beer.CacheHit, env.Meta.Cache)
+		require.Equal(t, "v1", env.Meta.Snapshot.Version)
+		require.InDelta(t, 60, env.Meta.Snapshot.AgeSeconds, 5)
+		require.Contains(t, env.Links.Self, "/beer/getFiltered?")
+		require.Contains(t, env.Links.Next, "cursor=n")
+		require.Empty(t, env.Links.Prev)
+	}
+
+	// an empty page is still an envelope
+	empty = true
+	rec = get("/beer/getFiltered?envelope=v1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var env map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	require.Equal(t, []any{}, env["data"])
+}
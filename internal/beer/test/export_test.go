@@ -0,0 +1,244 @@
+package test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func exportHandler(calls *int) beer.HTTPHandler {
+	svc := &mockService{
+		GetAllBeersFunc: func(page beer.Page) (beer.BeerPage, error) {
+			*calls++
+			return beer.BeerPage{Beers: exportCatalog(), Total: 2}, nil
+		},
+	}
+	return beer.NewHandler(svc, nil, &config.Configuration{})
+}
+
+func listAll(t *testing.T, h beer.HTTPHandler, target, accept string) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	return rec, h.ListAllBeers(setupEcho().NewContext(req, rec))
+}
+
+func TestListAllBeers_Negotiation(t *testing.T) {
+	calls := 0
+	h := exportHandler(&calls)
+
+	cases := []struct {
+		target, accept, contentType string
+	}{
+		{"/beer/getAll", "", "application/json"},
+		{"/beer/getAll", "*/*", "application/json"},
+		// browsers list application/xml after text/html
+		{"/beer/getAll", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "application/json"},
+		{"/beer/getAll", "text/csv", "text/csv; charset=utf-8"},
+		{"/beer/getAll", "application/xml;q=0.5, application/x-ndjson", "application/x-ndjson"},
+		{"/beer/getAll", "image/png, text/yaml;q=0.1", "application/yaml"},
+		// the most specific range decides, so json;q=0 overrides application/*
+		{"/beer/getAll", "application/*;q=0.2, application/json;q=0, text/yaml;q=0.1", "application/yaml"},
+		// format= takes precedence over Accept
+		{"/beer/getAll?format=XML", "text/csv", "application/xml; charset=utf-8"},
+	}
+	for _, tc := range cases {
+		rec, err := listAll(t, h, tc.target, tc.accept)
+		require.NoError(t, err, tc.accept)
+		require.Equal(t, http.StatusOK, rec.Code, tc.accept)
+		require.Equal(t, tc.contentType, rec.Header().Get("Content-Type"), tc.accept)
+		// every format shares the URL, so caches have to key on Accept
+		require.Equal(t, "Accept", rec.Header().Get("Vary"), tc.accept)
+	}
+	require.Equal(t, len(cases), calls)
+
+	// unsupported types are rejected before the service is called
+	for _, tc := range []struct{ target, accept string }{
+		{"/beer/getAll", "image/png"},
+		{"/beer/getAll", "text/csv;q=0"},
+		{"/beer/getAll", "text/*;q=1, text/csv;q=0"},
+		{"/beer/getAll?format=pdf", ""},
+	} {
+		_, err := listAll(t, h, tc.target, tc.accept)
+		var he *echo.HTTPError
+		require.ErrorAs(t, err, &he)
+		require.Equal(t, http.StatusNotAcceptable, he.Code)
+	}
+	require.Equal(t, len(cases), calls)
+}
+
+func TestListAllBeers_CSV(t *testing.T) {
+	calls := 0
+	h := exportHandler(&calls)
+
+	rec, err := listAll(t, h, "/beer/getAll?format=csv", "")
+	require.NoError(t, err)
+	require.Equal(t, "2", rec.Header().Get("X-Total-Count"))
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, []string{
+		"id", "name", "tagline", "first_brewed", "description", "abv", "ibu",
+		"ingredients.malt.name", "ingredients.malt.amount.value", "ingredients.malt.amount.unit",
+		"ingredients.hops.name", "ingredients.hops.amount.value", "ingredients.hops.amount.unit",
+		"ingredients.hops.add", "ingredients.hops.attribute", "ingredients.yeast",
+		"food_pairing", "brewers_tips", "contributed_by",
+	}, rows[0])
+
+	row := map[string]string{}
+	for i, col := range rows[0] {
+		row[col] = rows[1][i]
+	}
+	require.Equal(t, "1", row["id"])
+	require.Equal(t, "5.6", row["abv"])
+	require.Equal(t, "Citra; Simcoe", row["ingredients.hops.name"])
+	require.Equal(t, "10; 5", row["ingredients.hops.amount.value"])
+	require.Equal(t, "5.3", row["ingredients.malt.amount.value"])
+	require.Equal(t, "Spicy chicken; Cheesecake", row["food_pairing"])
+	require.Equal(t, `Say "Hi" & <Bye>`, rows[2][1])
+
+	// a projection selects the columns
+	rec, err = listAll(t, h, "/beer/getAll?format=csv&fields=name,ingredients.hops.name,id", "")
+	require.NoError(t, err)
+	rows, err = csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"id", "name", "ingredients.hops.name"},
+		{"1", "Punk IPA", "Citra; Simcoe"},
+		{"2", `Say "Hi" & <Bye>`, ""},
+	}, rows)
+}
+
+func TestListAllBeers_NDJSON(t *testing.T) {
+	calls := 0
+	h := exportHandler(&calls)
+
+	rec, err := listAll(t, h, "/beer/getAll?fields=id,name", "application/x-ndjson")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.Equal(t, map[string]any{"id": 1.0, "name": "Punk IPA"}, first)
+}
+
+func TestListAllBeers_XML(t *testing.T) {
+	calls := 0
+	h := exportHandler(&calls)
+
+	rec, err := listAll(t, h, "/beer/getAll?fields=id,name,food_pairing", "application/xml")
+	require.NoError(t, err)
+
+	var got struct {
+		XMLName xml.Name `xml:"beers"`
+		Beers   []struct {
+			ID    int      `xml:"id"`
+			Name  string   `xml:"name"`
+			Foods []string `xml:"food_pairing"`
+		} `xml:"beer"`
+	}
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got.Beers, 2)
+	require.Equal(t, 1, got.Beers[0].ID)
+	require.Equal(t, []string{"Spicy chicken", "Cheesecake"}, got.Beers[0].Foods)
+	require.Equal(t, `Say "Hi" & <Bye>`, got.Beers[1].Name)
+}
+
+func TestListAllBeers_YAML(t *testing.T) {
+	calls := 0
+	h := exportHandler(&calls)
+
+	rec, err := listAll(t, h, "/beer/getAll?format=yaml&fields=id,name,abv,ingredients.hops.name", "")
+	require.NoError(t, err)
+
+	var got []map[string]any
+	require.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, []map[string]any{
+		{"id": 1, "name": "Punk IPA", "abv": 5.6, "ingredients": map[string]any{"hops": []any{
+			map[string]any{"name": "Citra"}, map[string]any{"name": "Simcoe"},
+		}}},
+		{"id": 2, "name": `Say "Hi" & <Bye>`, "abv": 4, "ingredients": map[string]any{"hops": nil}},
+	}, got)
+}
+
+func TestSearchBeers_ExportKeepsScore(t *testing.T) {
+	svc := &mockService{
+		SearchBeersFunc: func(text string, page beer.Page, facets []string) (beer.SearchPage, error) {
+			return beer.SearchPage{Total: 1, Hits: []beer.SearchHit{{BeerResponse: exportCatalog()[0], Score: 1.5}}}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/beer/search?q=punk&fields=name&format=csv", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.SearchBeers(setupEcho().NewContext(req, rec)))
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"name", "score"}, {"Punk IPA", "1.5"}}, rows)
+}
+
+func TestFilteredBeers_EmptyExport(t *testing.T) {
+	svc := &mockService{}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/beer/getFiltered?format=yaml", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.FilteredBeers(setupEcho().NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestBeersByIDs_ExportFailureAbortsStream(t *testing.T) {
+	beers := exportCatalog()
+	beers[1].ABV = math.NaN()
+	svc := &mockService{
+		GetBeersByIDsFunc: func(ids []int) ([]backendbeer.BeerResponse, error) { return beers, nil },
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/beer?ids=1,2&format=ndjson", nil)
+	rec := httptest.NewRecorder()
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		_ = h.BeersByIDs(setupEcho().NewContext(req, rec))
+	})
+
+	// the status was already sent; only the first item made it out
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 1, strings.Count(rec.Body.String(), "\n"))
+	require.Contains(t, rec.Body.String(), `"Punk IPA"`)
+}
+
+func TestBeersByIDs_ExportVariesOnAccept(t *testing.T) {
+	svc := &mockService{
+		GetBeersByIDsFunc: func(ids []int) ([]backendbeer.BeerResponse, error) { return exportCatalog(), nil },
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	for _, accept := range []string{"", "text/csv", "application/x-ndjson"} {
+		req := httptest.NewRequest(http.MethodGet, "/beer?ids=1,2", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.BeersByIDs(setupEcho().NewContext(req, rec)), accept)
+		require.Equal(t, "Accept", rec.Header().Get("Vary"), accept)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFilteredBeers_Facets(t *testing.T) {
+	client := catalogClient(facetCatalog())
+	svc := newTestService(client, newTestConfig())
+
+	q := beer.BeerQuery{
+		Filters: beer.BeerFilter{ABV: beer.Range[float64]{Upper: beer.Bound[float64]{Value: 10, Inclusive: true, Set: true}}},
+		Page:    beer.Page{Limit: 1},
+		Facets:  []string{beer.FacetFood, beer.FacetStyle, beer.FacetDecade, beer.FacetABVBucket},
+	}
+	want := beer.Facets{
+		beer.FacetFood:      {{Value: "Cake", Count: 2}, {Value: "Chicken", Count: 2}, {Value: "chicken wings", Count: 1}, {Value: "Fish", Count: 1}},
+		beer.FacetStyle:     {{Value: "ipa", Count: 2}, {Value: "lager", Count: 1}, {Value: "stout", Count: 1}},
+		beer.FacetDecade:    {{Value: "1990s", Count: 1}, {Value: "2000s", Count: 2}, {Value: "2010s", Count: 1}},
+		beer.FacetABVBucket: {{Value: "0-4", Count: 1}, {Value: "5-6", Count: 1}, {Value: "6-7", Count: 1}, {Value: "10+", Count: 1}},
+	}
+
+	page, err := svc.GetFilteredBeers(q)
+	require.NoError(t, err)
+	require.Len(t, page.Beers, 1)
+	// facets count the whole result, not just the page
+	require.Equal(t, want, page.Facets)
+
+	// a cached result is counted the same way
+	page, err = svc.GetFilteredBeers(q)
+	require.NoError(t, err)
+	require.Equal(t, want, page.Facets)
+
+	page, err = svc.GetFilteredBeers(beer.BeerQuery{})
+	require.NoError(t, err)
+	require.Nil(t, page.Facets)
+
+	_, err = svc.GetFilteredBeers(beer.BeerQuery{Facets: []string{"colour"}})
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+}
+
+func TestSearchBeers_Facets(t *testing.T) {
+	client := catalogClient(facetCatalog())
+	svc := newTestService(client, newTestConfig())
+
+	page, err := svc.SearchBeers("citrus", beer.Page{Limit: 1}, []string{beer.FacetStyle})
+	require.NoError(t, err)
+	require.Len(t, page.Hits, 1)
+	require.Equal(t, beer.Facets{
+		beer.FacetStyle: {{Value: "ipa", Count: 2}, {Value: "stout", Count: 1}},
+	}, page.Facets)
+}
+
+func TestFilteredBeers_FacetsResponse(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{
+		GetDefaultQueryFunc: func() beer.BeerQuery { return beer.BeerQuery{} },
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			page := beer.BeerPage{Beers: []backendbeer.BeerResponse{{ID: 1, Name: "Hop IPA"}}, Total: 1}
+			if len(q.Facets) > 0 {
+				page.Facets = beer.Facets{beer.FacetStyle: {{Value: "ipa", Count: 1}}}
+			}
+			return page, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/getFiltered?facets=Style,style&fields=id", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.FilteredBeers(e.NewContext(req, rec)))
+	require.Equal(t, []string{beer.FacetStyle}, svc.LastQuery.Facets)
+	require.JSONEq(t, `{"results": [{"id": 1}], "facets": {"style": [{"value": "ipa", "count": 1}]}}`, rec.Body.String())
+
+	// without facets the body stays a bare array
+	req = httptest.NewRequest(http.MethodGet, "/getFiltered?fields=id", nil)
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.FilteredBeers(e.NewContext(req, rec)))
+	require.JSONEq(t, `[{"id": 1}]`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/getFiltered?facets=colour", nil)
+	var ve *beer.ValidationError
+	require.ErrorAs(t, h.FilteredBeers(e.NewContext(req, httptest.NewRecorder())), &ve)
+	require.Equal(t, "facets", ve.Errors[0].Field)
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runExpr(t *testing.T, engine beer.Engine, src string) []int {
+	t.Helper()
+	q := beer.BeerQuery{Filters: beer.BeerFilter{Expr: beer.NewFilterExpr(src)}}
+	require.NoError(t, q.Validate())
+	return ids(engine.Compile(q).Run(exprCatalog()))
+}
+
+func TestFilterExpr_Compile(t *testing.T) {
+	engine := beer.Engine{}
+	cases := map[string][]int{
+		`abv >= 5 and abv < 7 and (food ~ "chicken" or food ~ "lamb") and not style = "stout"`: {1, 3},
+		`style = "IPA" or style = "lager"`:                 {1, 5},
+		`first_brewed >= "2017-01" and brewed < "2019-01"`: {2, 3},
+		`year > 2016`: {2, 3, 5},
+		`hops ~ "citra" or ingredients.hops.name = "CASCADE"`: {1, 3},
+		`hop_attribute = "aroma" and name !~ "ruby"`:          {3},
+		`food = "fish" or id = 4`:                             {4, 5},
+		`tagline != "stout" and ibu = 0 and abv <= 4.5`:       {5},
+	}
+	for src, want := range cases {
+		require.Equal(t, want, runExpr(t, engine, src), src)
+	}
+}
+
+func TestFilterExpr_SameResultAsParameters(t *testing.T) {
+	engine := beer.Engine{Synonyms: beer.NewSynonyms(map[string][]string{"chicken": {"poultry"}})}
+
+	params := engine.Compile(beer.BeerQuery{Filters: beer.BeerFilter{
+		Foods:  []string{"chicken"},
+		ABV:    beer.Range[float64]{Lower: beer.Bound[float64]{Value: 5, Inclusive: true, Set: true}},
+		Styles: []beer.Style{beer.StyleIPA, beer.StyleStrongAle},
+	}}).Run(exprCatalog())
+
+	require.Equal(t, ids(params), runExpr(t, engine, `food ~ "chicken" and abv >= 5 and (style = "ipa" or style = "strong-ale")`))
+}
+
+func TestFilterExpr_TypeErrors(t *testing.T) {
+	cases := []struct {
+		src      string
+		position int
+		msg      string
+	}{
+		{`colour = "red"`, 1, `unknown field "colour"`},
+		{`abv ~ 5`, 5, `operator "~" cannot be used with abv`},
+		{`abv > "five"`, 7, `abv is a number field, got string "five"`},
+		{`name = 5`, 8, `name is a string field, got number 5`},
+		{`brewed < "2019"`, 10, `must be a month in yyyy-mm format`},
+		{`style = "mead"`, 9, `unknown style`},
+		{`style < "ipa"`, 7, `operator "<" cannot be used with style`},
+		{`abv > 5 and (name ~ "x"`, 24, `expected ")"`},
+	}
+
+	for _, tc := range cases {
+		err := beer.BeerQuery{Filters: beer.BeerFilter{Expr: beer.NewFilterExpr(tc.src)}}.Validate()
+		var ve *beer.ValidationError
+		require.ErrorAs(t, err, &ve, tc.src)
+		require.Len(t, ve.Errors, 1, tc.src)
+		require.Equal(t, "q", ve.Errors[0].Field)
+		require.Equal(t, tc.position, ve.Errors[0].Position, tc.src)
+		require.Contains(t, ve.Errors[0].Message, tc.msg, tc.src)
+	}
+}
+
+func TestFilterExpr_CacheKeyIsCanonical(t *testing.T) {
+	a := beer.BeerQuery{Filters: beer.BeerFilter{Expr: beer.NewFilterExpr(`abv>5 AND food ~ 'lamb'`)}}
+	b := beer.BeerQuery{Filters: beer.BeerFilter{Expr: beer.NewFilterExpr(`abv > 5 and food ~ "lamb"`)}}
+	require.Equal(t, a.Key(), b.Key())
+}
+
+func TestFilteredBeers_ExprParam(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, `/getFiltered?q=abv+%3E%3D+5+and+not+style+%3D+%22stout%22`, nil)
+	require.NoError(t, h.FilteredBeers(e.NewContext(req, httptest.NewRecorder())))
+	require.Equal(t, `abv >= 5 and not style = "stout"`, svc.LastQuery.Filters.Expr.String())
+}
@@ -0,0 +1,208 @@
+package test
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"interview-go/internal/cache"
+	"time"
+)
+
+func newTestConfig() *config.Configuration {
+	cfg := &config.Configuration{}
+	cfg.Cache.TTL = time.Minute
+	cfg.Cache.ClearTicker = time.Minute
+	cfg.Cache.NegativeTTL = time.Minute
+	cfg.ApiRateLimit.Rate = time.Minute
+	cfg.ApiRateLimit.Burst = 10
+	return cfg
+}
+
+func newTestService(client backendbeer.Client, cfg *config.Configuration) beer.Service {
+	return beer.NewService(client, cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker), cfg)
+}
+
+// catalogClient serves beers as the upstream catalog.
+func catalogClient(beers []backendbeer.BeerResponse) *mockClient {
+	return &mockClient{ListBeersFunc: func() ([]backendbeer.BeerResponse, error) { return beers, nil }}
+}
+
+func ids(beers []backendbeer.BeerResponse) []int {
+	out := make([]int, 0, len(beers))
+	for _, b := range beers {
+		out = append(out, b.ID)
+	}
+	return out
+}
+
+func amount(v float64, unit string) backendbeer.Amount {
+	return backendbeer.Amount{Value: v, Unit: unit}
+}
+
+func grams(v float64) backendbeer.Amount {
+	return amount(v, "grams")
+}
+
+// ingredientsOf lists a malt and hops by name only; an empty malt is left out.
+func ingredientsOf(malt string, hops ...string) backendbeer.Ingredients {
+	var in backendbeer.Ingredients
+	if malt != "" {
+		in.Malt = []backendbeer.Malt{{Name: malt}}
+	}
+	for _, h := range hops {
+		in.Hops = append(in.Hops, backendbeer.Hops{Name: h})
+	}
+	return in
+}
+
+// catalogOf returns n plain beers with IDs and ABVs 1..n.
+func catalogOf(n int) []backendbeer.BeerResponse {
+	beers := make([]backendbeer.BeerResponse, 0, n)
+	for i := 1; i <= n; i++ {
+		beers = append(beers, backendbeer.BeerResponse{ID: i, Name: "Beer", ABV: float64(i)})
+	}
+	return beers
+}
+
+func ingredientCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{ID: 1, Ingredients: backendbeer.Ingredients{
+			Malt:  []backendbeer.Malt{{Name: "Extra Pale", Amount: amount(5, "kilograms")}},
+			Hops:  []backendbeer.Hops{{Name: "Citra", Amount: grams(25), Add: "end", Attribute: "aroma"}},
+			Yeast: "Wyeast 1056 - American Ale™",
+		}},
+		{ID: 2, Ingredients: backendbeer.Ingredients{
+			Malt:  []backendbeer.Malt{{Name: "Caramalt", Amount: grams(250)}},
+			Hops:  []backendbeer.Hops{{Name: "Citra", Amount: grams(10), Add: "start", Attribute: "bitter"}},
+			Yeast: "Wyeast 3711 - French Saison™",
+		}},
+		{ID: 3, Ingredients: backendbeer.Ingredients{
+			Malt:  []backendbeer.Malt{{Name: "Extra Pale", Amount: amount(4, "kilograms")}},
+			Hops:  []backendbeer.Hops{{Name: "Cascade", Amount: amount(2, "oz"), Add: "middle", Attribute: "Flavour"}},
+			Yeast: "Wyeast 1056 - American Ale™",
+		}},
+	}
+}
+
+func foodCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{ID: 1, FoodPairing: []string{"Spicy chicken tikka masala", "Grilled lamb"}},
+		{ID: 2, FoodPairing: []string{"Roast poultry with herbs"}},
+		{ID: 3, FoodPairing: []string{"Smoked SALMON"}},
+		{ID: 4, FoodPairing: []string{"Chickens wings", "Lamb kofta"}},
+	}
+}
+
+func pairingCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{ID: 1, Name: "A", FoodPairing: []string{"Grilled Chicken", "grilled chicken!", "Lamb"}},
+		{ID: 2, Name: "B", FoodPairing: []string{"Lamb"}},
+		{ID: 3, Name: "C", FoodPairing: []string{"grilled  chicken"}},
+		{ID: 4, Name: "D", FoodPairing: []string{"Chocolate cake", "???"}},
+		{ID: 5, Name: "E", FoodPairing: []string{"lamb"}},
+	}
+}
+
+func facetCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{ID: 1, Name: "Hop IPA", Description: "citrus", FirstBrewed: "1995-01", ABV: 6.2, FoodPairing: []string{"Chicken", "chicken wings"}},
+		{ID: 2, Name: "Dark", Tagline: "Stout", Description: "citrus", FirstBrewed: "2003-02", ABV: 10, FoodPairing: []string{"chicken", "Cake"}},
+		{ID: 3, Name: "Citrus IPA", Description: "citrus", FirstBrewed: "2004-03", ABV: 3.9, FoodPairing: []string{"Cake"}},
+		{ID: 4, Name: "Plain", Tagline: "Lager", FirstBrewed: "2010-04", ABV: 5, FoodPairing: []string{"Fish"}},
+	}
+}
+
+func exprCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{ID: 1, Name: "Ruby IPA", FirstBrewed: "2016-01", ABV: 6.0, FoodPairing: []string{"Spicy chicken"},
+			Ingredients: backendbeer.Ingredients{Hops: []backendbeer.Hops{{Name: "Citra", Attribute: "aroma"}}}},
+		{ID: 2, Name: "Night", Tagline: "Stout", FirstBrewed: "2017-05", ABV: 6.5, FoodPairing: []string{"Lamb stew"}},
+		{ID: 3, Name: "Pale", Tagline: "English Pale Ale", FirstBrewed: "2018-03", ABV: 5.2, FoodPairing: []string{"Grilled lamb"},
+			Ingredients: backendbeer.Ingredients{Hops: []backendbeer.Hops{{Name: "Cascade", Attribute: "aroma"}}}},
+		{ID: 4, Name: "Big One", Tagline: "Strong Ale", FirstBrewed: "2014-12", ABV: 8.5, FoodPairing: []string{"Roast chicken"}},
+		{ID: 5, Name: "Light", Tagline: "Pilsner", FirstBrewed: "2019-07", ABV: 4.5, FoodPairing: []string{"Fish"}},
+	}
+}
+
+func statsCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{ID: 1, Name: "A IPA", FirstBrewed: "1995-01", ABV: 4, FoodPairing: []string{"Chicken", "chicken", "Lamb"}, Ingredients: ingredientsOf("Extra Pale", "Citra", "Citra", "Cascade")},
+		{ID: 2, Name: "B", FirstBrewed: "1999-05", ABV: 6, FoodPairing: []string{"Lamb"}, Ingredients: ingredientsOf("Extra Pale", "Cascade")},
+		{ID: 3, Name: "C IPA", FirstBrewed: "2003-03", ABV: 8, FoodPairing: []string{"Fish"}, Ingredients: ingredientsOf("Extra Pale", "Citra")},
+		{ID: 4, Name: "D", FirstBrewed: "2003-12", ABV: 10, FoodPairing: []string{"chicken"}, Ingredients: ingredientsOf("Extra Pale", "Simcoe")},
+	}
+}
+
+func similarCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{ID: 1, Name: "Hop IPA", ABV: 6, FoodPairing: []string{"Spicy chicken curry"}, Ingredients: ingredientsOf("Pale", "Citra", "Cascade")},
+		{ID: 2, Name: "Other IPA", ABV: 6.5, FoodPairing: []string{"Grilled chicken"}, Ingredients: ingredientsOf("pale", "cascade", "Citra")},
+		{ID: 3, Name: "Dark Stout", ABV: 9, FoodPairing: []string{"Chocolate cake"}, Ingredients: ingredientsOf("Roasted Barley", "Fuggles")},
+		{ID: 4, Name: "Hazy Wheat", ABV: 5, Ingredients: ingredientsOf("Pale", "Citra")},
+		{ID: 5, Name: "Mystery", ABV: 20, Ingredients: ingredientsOf("Rye", "Saaz")},
+	}
+}
+
+func compareCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{
+			ID: 1, Name: "Punk IPA", FirstBrewed: "2007-04", ABV: 5.6, IBU: 40,
+			FoodPairing: []string{"Spicy chicken", "Cheesecake"},
+			Ingredients: backendbeer.Ingredients{
+				Hops:  []backendbeer.Hops{{Name: "Citra", Amount: grams(10)}, {Name: "Simcoe", Amount: grams(5)}, {Name: "Citra", Amount: grams(15)}},
+				Malt:  []backendbeer.Malt{{Name: "Extra Pale", Amount: amount(5.3, "kilograms")}},
+				Yeast: "Wyeast 1056",
+			},
+		},
+		{
+			ID: 7, Name: "Dead Pony Club", FirstBrewed: "2010-01", ABV: 3.8,
+			FoodPairing: []string{"spicy  chicken", "Nachos"},
+			Ingredients: backendbeer.Ingredients{
+				Hops:  []backendbeer.Hops{{Name: "citra", Amount: grams(20)}, {Name: "Mosaic", Amount: grams(20)}},
+				Malt:  []backendbeer.Malt{{Name: "Extra Pale"}, {Name: "Caramalt"}},
+				Yeast: "Wyeast 1056",
+			},
+		},
+		{
+			ID: 42, Name: "Tokyo Stout", FirstBrewed: "2008-09", ABV: 18.2,
+			FoodPairing: []string{"Spicy chicken", "Nachos"},
+			Ingredients: backendbeer.Ingredients{
+				Hops:  []backendbeer.Hops{{Name: "Citra"}, {Name: "Simcoe"}},
+				Malt:  []backendbeer.Malt{{Name: "Extra Pale"}, {Name: "Chocolate"}},
+				Yeast: "Wyeast 1272",
+			},
+		},
+	}
+}
+
+func projectionBeer() backendbeer.BeerResponse {
+	return backendbeer.BeerResponse{
+		ID: 1, Name: "Ruby IPA", ABV: 6.5, Description: "long text",
+		Ingredients: backendbeer.Ingredients{
+			Malt: []backendbeer.Malt{{Name: "Extra Pale", Amount: amount(5, "kilograms")}},
+			Hops: []backendbeer.Hops{
+				{Name: "Cascade", Amount: grams(25), Add: "start", Attribute: "bitter"},
+				{Name: "Citra", Amount: grams(25), Add: "end", Attribute: "aroma"},
+			},
+			Yeast: "Wyeast 1056",
+		},
+	}
+}
+
+func exportCatalog() []backendbeer.BeerResponse {
+	return []backendbeer.BeerResponse{
+		{
+			ID: 1, Name: "Punk IPA", FirstBrewed: "2007-04", ABV: 5.6,
+			FoodPairing: []string{"Spicy chicken", "Cheesecake"},
+			Ingredients: backendbeer.Ingredients{
+				Hops: []backendbeer.Hops{
+					{Name: "Citra", Amount: grams(10), Add: "start", Attribute: "bitter"},
+					{Name: "Simcoe", Amount: grams(5), Add: "end", Attribute: "aroma"},
+				},
+				Malt:  []backendbeer.Malt{{Name: "Extra Pale", Amount: amount(5.3, "kilograms")}},
+				Yeast: "Wyeast 1056",
+			},
+		},
+		{ID: 2, Name: `Say "Hi" & <Bye>`, ABV: 4},
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"errors"
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/beer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPairsWithFoods_SubstringAndCase(t *testing.T) {
+	q := beer.Query{Filter: beer.PairsWithFoods([]string{"CHICKEN"}, beer.MatchAny, nil)}
+	require.Equal(t, []int{1, 4}, ids(q.Run(foodCatalog())))
+
+	q = beer.Query{Filter: beer.PairsWithFoods([]string{"tikka"}, beer.MatchAny, nil)}
+	require.Equal(t, []int{1}, ids(q.Run(foodCatalog())))
+}
+
+func TestPairsWithFoods_StemmedWords(t *testing.T) {
+	q := beer.Query{Filter: beer.PairsWithFoods([]string{"wing"}, beer.MatchAny, nil)}
+	require.Equal(t, []int{4}, ids(q.Run(foodCatalog())))
+
+	q = beer.Query{Filter: beer.PairsWithFoods([]string{"masala chicken"}, beer.MatchAny, nil)}
+	require.Equal(t, []int{1}, ids(q.Run(foodCatalog())))
+}
+
+func TestPairsWithFoods_AnyAndAll(t *testing.T) {
+	foods := []string{"chicken", "lamb", "salmon"}
+
+	q := beer.Query{Filter: beer.PairsWithFoods(foods, beer.MatchAny, nil)}
+	require.Equal(t, []int{1, 3, 4}, ids(q.Run(foodCatalog())))
+
+	q = beer.Query{Filter: beer.PairsWithFoods(foods[:2], beer.MatchAll, nil)}
+	require.Equal(t, []int{1, 4}, ids(q.Run(foodCatalog())))
+
+	q = beer.Query{Filter: beer.PairsWithFoods(foods, beer.MatchAll, nil)}
+	require.Empty(t, q.Run(foodCatalog()))
+}
+
+func TestPairsWithFoods_Synonyms(t *testing.T) {
+	syn := beer.NewSynonyms(map[string][]string{
+		"Chicken": {"poultry"},
+		"fish":    {"salmon", "cod"},
+	})
+
+	q := beer.Query{Filter: beer.PairsWithFoods([]string{"chicken"}, beer.MatchAny, syn)}
+	require.Equal(t, []int{1, 2, 4}, ids(q.Run(foodCatalog())))
+
+	// synonyms apply both ways, but not between siblings
+	q = beer.Query{Filter: beer.PairsWithFoods([]string{"poultry"}, beer.MatchAny, syn)}
+	require.Equal(t, []int{1, 2, 4}, ids(q.Run(foodCatalog())))
+	q = beer.Query{Filter: beer.PairsWithFoods([]string{"cod"}, beer.MatchAny, syn)}
+	require.Empty(t, q.Run(foodCatalog()))
+}
+
+func TestPairsWithFoods_SynonymsMatchWholeWords(t *testing.T) {
+	syn := beer.NewSynonyms(map[string][]string{"pork": {"ham"}})
+	catalog := []backendbeer.BeerResponse{
+		{ID: 1, FoodPairing: []string{"Graham crackers"}},
+		{ID: 2, FoodPairing: []string{"Hamburger"}},
+		{ID: 3, FoodPairing: []string{"Glazed ham"}},
+		{ID: 4, FoodPairing: []string{"Pulled porky buns"}},
+	}
+
+	// the requested food still matches as a substring, its synonyms do not
+	q := beer.Query{Filter: beer.PairsWithFoods([]string{"pork"}, beer.MatchAny, syn)}
+	require.Equal(t, []int{3, 4}, ids(q.Run(catalog)))
+}
+
+func TestEngine_CompileUsesSynonyms(t *testing.T) {
+	engine := beer.Engine{Synonyms: beer.NewSynonyms(map[string][]string{"chicken": {"poultry"}})}
+
+	q := engine.Compile(beer.BeerQuery{Filters: beer.BeerFilter{Foods: []string{"chicken"}, FoodMatch: beer.MatchAll}})
+	require.Equal(t, []int{1, 2, 4}, ids(q.Run(foodCatalog())))
+}
+
+func TestBeerQueryValidate_Foods(t *testing.T) {
+	err := beer.BeerQuery{Filters: beer.BeerFilter{Foods: []string{"chicken", " ,"}, FoodMatch: "most"}}.Validate()
+	require.True(t, errors.Is(err, beer.ErrInvalidQuery))
+
+	var ve *beer.ValidationError
+	require.True(t, errors.As(err, &ve))
+	require.Len(t, ve.Errors, 2)
+	require.Equal(t, "hasFood", ve.Errors[0].Field)
+	require.Equal(t, "match", ve.Errors[1].Field)
+}
+
+func TestBeerQueryKey_SeparatesYearAndFoods(t *testing.T) {
+	a := beer.BeerQuery{Filters: beer.BeerFilter{Year: 201, Foods: []string{"5wolf"}}}
+	b := beer.BeerQuery{Filters: beer.BeerFilter{Year: 2015, Foods: []string{"wolf"}}}
+	require.NotEqual(t, a.Key(), b.Key())
+}
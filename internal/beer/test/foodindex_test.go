@@ -0,0 +1,125 @@
+package test
+
+import (
+	"encoding/json"
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFoods_CountsNormalizedPairings(t *testing.T) {
+	client := catalogClient(pairingCatalog())
+	svc := newTestService(client, newTestConfig())
+
+	foods, err := svc.GetFoods()
+	require.NoError(t, err)
+	// repeated pairings count a beer once; ties sort by name
+	require.Equal(t, []beer.ValueCount{
+		{Value: "lamb", Count: 3},
+		{Value: "grilled chicken", Count: 2},
+		{Value: "chocolate cake", Count: 1},
+	}, foods)
+}
+
+func TestGetBeersByFood_Lookup(t *testing.T) {
+	client := catalogClient(pairingCatalog())
+	svc := newTestService(client, newTestConfig())
+
+	page, err := svc.GetBeersByFood("Grilled CHICKEN", beer.Page{})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3}, ids(page.Beers))
+	require.Equal(t, 2, page.Total)
+
+	page, err = svc.GetBeersByFood("lamb", beer.Page{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, ids(page.Beers))
+	require.NotEmpty(t, page.Next)
+
+	page, err = svc.GetBeersByFood("lamb", beer.Page{Cursor: page.Next})
+	require.NoError(t, err)
+	require.Equal(t, []int{5}, ids(page.Beers))
+
+	// the index matches whole pairings, not words within them
+	_, err = svc.GetBeersByFood("chicken", beer.Page{})
+	require.ErrorIs(t, err, beer.ErrFoodNotFound)
+
+	_, err = svc.GetBeersByFood("!!", beer.Page{})
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, "food", ve.Errors[0].Field)
+}
+
+func TestGetFoods_RebuiltOnRefresh(t *testing.T) {
+	catalog := pairingCatalog()
+	client := &mockClient{ListBeersFunc: func() ([]backendbeer.BeerResponse, error) { return catalog, nil }}
+	cfg := newTestConfig()
+	cfg.Cache.TTL = 10 * time.Millisecond
+	svc := newTestService(client, cfg)
+
+	_, err := svc.GetBeersByFood("fish tacos", beer.Page{})
+	require.ErrorIs(t, err, beer.ErrFoodNotFound)
+
+	catalog = append(catalog, backendbeer.BeerResponse{ID: 6, Name: "F", FoodPairing: []string{"Fish tacos"}})
+	time.Sleep(20 * time.Millisecond)
+
+	page, err := svc.GetBeersByFood("fish tacos", beer.Page{})
+	require.NoError(t, err)
+	require.Equal(t, []int{6}, ids(page.Beers))
+
+	foods, err := svc.GetFoods()
+	require.NoError(t, err)
+	require.Contains(t, foods, beer.ValueCount{Value: "fish tacos", Count: 1})
+}
+
+func TestFoodHandlers(t *testing.T) {
+	e := setupEcho()
+	var gotFood string
+	svc := &mockService{
+		GetFoodsFunc: func() ([]beer.ValueCount, error) {
+			return []beer.ValueCount{{Value: "lamb", Count: 3}}, nil
+		},
+		GetBeersByFoodFunc: func(food string, page beer.Page) (beer.BeerPage, error) {
+			gotFood = food
+			if food == "tofu" {
+				return beer.BeerPage{}, beer.ErrFoodNotFound
+			}
+			require.Equal(t, beer.Page{Limit: 1}, page)
+			return beer.BeerPage{Beers: catalogOf(1), Total: 3, Limit: 1, Next: "n"}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.Foods(e.NewContext(httptest.NewRequest(http.MethodGet, "/foods", nil), rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var foods []beer.ValueCount
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &foods))
+	require.Equal(t, []beer.ValueCount{{Value: "lamb", Count: 3}}, foods)
+
+	foodBeers := func(food, query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/foods/"+food+"/beers"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("food")
+		c.SetParamValues(food)
+		return rec, h.FoodBeers(c)
+	}
+
+	rec, err := foodBeers("grilled%20chicken", "?limit=1")
+	require.NoError(t, err)
+	require.Equal(t, "grilled chicken", gotFood)
+	require.Equal(t, "3", rec.Header().Get("X-Total-Count"))
+	require.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+
+	_, err = foodBeers("tofu", "")
+	var he *echo.HTTPError
+	require.ErrorAs(t, err, &he)
+	require.Equal(t, http.StatusNotFound, he.Code)
+}
@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	backendbeer "interview-go/backend/client"
+	"interview-go/config"
 	"interview-go/internal/beer"
+	"interview-go/internal/cache"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
@@ -18,35 +22,35 @@ func setupEcho() *echo.Echo {
 	return e
 }
 
-func TestFilteredBeers_DefaultFilteringAndSorting(t *testing.T) {
+func TestFilteredBeers_DefaultFilters(t *testing.T) {
 	e := setupEcho()
 
-	defaultFilters := beer.BeerFilter{
-		IncludeIpa:   true,
-		Year:         2015,
-		HasFood:      "wolf",
-		AbvSortOrder: "asc",
+	defaultQuery := beer.BeerQuery{
+		Filters: beer.BeerFilter{
+			Styles: []beer.Style{beer.StyleIPA},
+			Year:   2015,
+			Foods:  []string{"wolf"},
+		},
+		Sort: []beer.SortKey{{Field: "abv"}},
 	}
 
 	svc := &mockService{
-		GetDefaultFiltersFunc: func() beer.BeerFilter { return defaultFilters },
-		GetFilteredBeersFunc: func(filters string) ([]backendbeer.BeerResponse, error) {
-			return []backendbeer.BeerResponse{
+		GetDefaultQueryFunc: func() beer.BeerQuery { return defaultQuery },
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{
 				{ID: 1, Name: "Ruby IPA", FirstBrewed: "2016-01", ABV: 6.0, FoodPairing: []string{"wolf", "steak"}},
-				{ID: 2, Name: "Lager", FirstBrewed: "2017-05", ABV: 4.5, FoodPairing: []string{"wolf"}},
-				{ID: 3, Name: "Pale Ale", FirstBrewed: "2018-03", ABV: 5.2, FoodPairing: []string{"pizza"}},
-				{ID: 4, Name: "Imperial IPA", FirstBrewed: "2014-12", ABV: 8.5, FoodPairing: []string{"wolf"}},
-			}, nil
+			}, Total: 1}, nil
 		},
 	}
 
-	h := beer.NewHandler(svc)
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
 	req := httptest.NewRequest(http.MethodGet, "/getFiltered", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
 	require.NoError(t, h.FilteredBeers(c))
 	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, defaultQuery, svc.LastQuery)
 
 	var got []backendbeer.BeerResponse
 	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
@@ -58,45 +62,46 @@ func TestFilteredBeers_DefaultFilteringAndSorting(t *testing.T) {
 func TestFilteredBeers_QueryOverrides(t *testing.T) {
 	e := setupEcho()
 
-	defaultFilters := beer.BeerFilter{IncludeIpa: true, Year: 2015, HasFood: "wolf", AbvSortOrder: "asc"}
+	defaultQuery := beer.BeerQuery{
+		Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleIPA}, Year: 2015, Foods: []string{"wolf"}},
+		Sort:    []beer.SortKey{{Field: "abv"}},
+	}
 
 	svc := &mockService{
-		GetDefaultFiltersFunc: func() beer.BeerFilter { return defaultFilters },
-		GetFilteredBeersFunc: func(filters string) ([]backendbeer.BeerResponse, error) {
-			return []backendbeer.BeerResponse{
-				{ID: 10, Name: "Dark Lager", FirstBrewed: "2020-01", ABV: 7.2, FoodPairing: []string{"fish"}},
-				{ID: 11, Name: "Summer Ale", FirstBrewed: "2021-06", ABV: 4.0, FoodPairing: []string{"fish"}},
+		GetDefaultQueryFunc: func() beer.BeerQuery { return defaultQuery },
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{
 				{ID: 12, Name: "Tropical IPA", FirstBrewed: "2022-03", ABV: 5.5, FoodPairing: []string{"fish"}},
-			}, nil
+			}, Total: 1}, nil
 		},
 	}
 
-	h := beer.NewHandler(svc)
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
 	req := httptest.NewRequest(http.MethodGet, "/getFiltered?includeIpa=false&year=2020&hasFood=fish&abvSortOrder=desc", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
 	require.NoError(t, h.FilteredBeers(c))
 	require.Equal(t, http.StatusOK, rec.Code)
-
-	var got []backendbeer.BeerResponse
-	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
-
-	require.Len(t, got, 2)
-	require.Equal(t, 12, got[0].ID) // higher ABV first due to desc
-	require.Equal(t, 11, got[1].ID)
+	require.Equal(t, beer.BeerQuery{
+		Filters: beer.BeerFilter{Year: 2020, Foods: []string{"fish"}},
+		Sort:    []beer.SortKey{{Field: "abv", Desc: true}},
+	}, svc.LastQuery)
 }
 
 func TestFilteredBeers_EmptyServiceResponse(t *testing.T) {
 	e := setupEcho()
-	defaultFilters := beer.BeerFilter{IncludeIpa: true, Year: 2015, HasFood: "wolf", AbvSortOrder: "asc"}
+	defaultQuery := beer.BeerQuery{
+		Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleIPA}, Year: 2015, Foods: []string{"wolf"}},
+		Sort:    []beer.SortKey{{Field: "abv"}},
+	}
 	svc := &mockService{
-		GetDefaultFiltersFunc: func() beer.BeerFilter { return defaultFilters },
-		GetFilteredBeersFunc: func(filters string) ([]backendbeer.BeerResponse, error) {
-			return []backendbeer.BeerResponse{}, nil
+		GetDefaultQueryFunc: func() beer.BeerQuery { return defaultQuery },
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			return beer.BeerPage{}, nil
 		},
 	}
-	h := beer.NewHandler(svc)
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
 	req := httptest.NewRequest(http.MethodGet, "/getFiltered", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
@@ -109,12 +114,12 @@ func TestFilteredBeers_EmptyServiceResponse(t *testing.T) {
 func TestFilteredBeers_ServiceError(t *testing.T) {
 	e := setupEcho()
 	svc := &mockService{
-		GetDefaultFiltersFunc: func() beer.BeerFilter { return beer.BeerFilter{} },
-		GetFilteredBeersFunc: func(filters string) ([]backendbeer.BeerResponse, error) {
-			return nil, errors.New("some error")
+		GetDefaultQueryFunc: func() beer.BeerQuery { return beer.BeerQuery{} },
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			return beer.BeerPage{}, errors.New("some error")
 		},
 	}
-	h := beer.NewHandler(svc)
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
 	req := httptest.NewRequest(http.MethodGet, "/getFiltered", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
@@ -129,13 +134,15 @@ func TestFilteredBeers_ServiceError(t *testing.T) {
 func TestFilteredBeers_InvalidQueryParams(t *testing.T) {
 	e := setupEcho()
 	svc := &mockService{
-		GetDefaultFiltersFunc: func() beer.BeerFilter { return beer.BeerFilter{} },
+		GetDefaultQueryFunc: func() beer.BeerQuery { return beer.BeerQuery{} },
 	}
-	h := beer.NewHandler(svc)
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
 
 	cases := []string{
 		"includeIpa=notabool",
 		"year=notanint",
+		"abvSortOrder=sideways",
+		"sort=abv,,name",
 	}
 
 	for _, q := range cases {
@@ -150,3 +157,291 @@ func TestFilteredBeers_InvalidQueryParams(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, httpErr.Code, q)
 	}
 }
+
+func TestListAllBeers_EncodedResponseETag(t *testing.T) {
+	e := setupEcho()
+
+	calls := 0
+	svc := &mockService{
+		GetAllBeersFunc: func(page beer.Page) (beer.BeerPage, error) {
+			calls++
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{{ID: 1, Name: "Ruby IPA"}}, Total: 1}, nil
+		},
+	}
+
+	cfg := &config.Configuration{}
+	cfg.Cache.TTL = time.Minute
+	cfg.Cache.ClearTicker = time.Minute
+	h := beer.NewHandler(svc, cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker), cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/getAll", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ListAllBeers(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.Equal(t, "max-age=60", rec.Header().Get("Cache-Control"))
+
+	req = httptest.NewRequest(http.MethodGet, "/getAll", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.ListAllBeers(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Empty(t, rec.Body.Bytes())
+	require.Equal(t, etag, rec.Header().Get("ETag"))
+
+	require.Equal(t, 1, calls)
+}
+
+func TestFilteredBeers_EncodedResponseETag(t *testing.T) {
+	e := setupEcho()
+
+	calls := 0
+	svc := &mockService{
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			calls++
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{{ID: calls, Name: strings.Join(q.Filters.Foods, ",")}}, Total: 1}, nil
+		},
+	}
+
+	cfg := &config.Configuration{}
+	cfg.Cache.TTL = time.Minute
+	h := beer.NewHandler(svc, cache.NewInMemory(time.Minute, time.Minute), cfg)
+
+	get := func(target, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.FilteredBeers(e.NewContext(req, rec)))
+		return rec
+	}
+
+	const target = "/getFiltered?hasFood=chicken&fields=id,name"
+	rec := get(target, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec = get(target, etag)
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Empty(t, rec.Body.Bytes())
+	require.Equal(t, etag, rec.Header().Get("ETag"))
+	require.Equal(t, 1, calls)
+
+	// another query or projection is neither served nor validated by that ETag
+	for _, other := range []string{"/getFiltered?hasFood=lamb&fields=id,name", "/getFiltered?hasFood=chicken&fields=id"} {
+		rec = get(other, etag)
+		require.Equal(t, http.StatusOK, rec.Code, other)
+		require.NotEmpty(t, rec.Body.Bytes(), other)
+		require.NotEqual(t, etag, rec.Header().Get("ETag"), other)
+	}
+	require.Equal(t, 3, calls)
+}
+
+func TestFilteredBeers_InvalidQuerySkipsCaches(t *testing.T) {
+	e := setupEcho()
+	client := catalogClient(catalogOf(3))
+	cfg := newTestConfig()
+	beers := cache.NewCounting(cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker))
+	responses := cache.NewCounting(cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker))
+	h := beer.NewHandler(beer.NewService(client, beers, cfg), responses, cfg)
+
+	for _, target := range []string{"/getFiltered?sort=bogus", "/getFiltered?limit=-1"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		var he *echo.HTTPError
+		require.ErrorAs(t, h.FilteredBeers(e.NewContext(req, httptest.NewRecorder())), &he, target)
+		require.Equal(t, http.StatusBadRequest, he.Code, target)
+	}
+
+	require.Zero(t, client.Calls)
+	require.Equal(t, cache.Stats{}, beers.Stats())
+	require.Equal(t, cache.Stats{}, responses.Stats())
+}
+
+func TestListAllBeers_EncodedResponsePerSnapshot(t *testing.T) {
+	e := setupEcho()
+
+	calls, version := 0, "v1"
+	svc := &mockService{
+		GetAllBeersFunc: func(page beer.Page) (beer.BeerPage, error) {
+			calls++
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{{ID: calls}}, Total: 1}, nil
+		},
+		SnapshotFunc: func() (beer.SnapshotInfo, error) {
+			return beer.SnapshotInfo{Version: version}, nil
+		},
+	}
+	cfg := &config.Configuration{}
+	cfg.Cache.TTL = time.Minute
+	h := beer.NewHandler(svc, cache.NewInMemory(time.Minute, time.Minute), cfg)
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.ListAllBeers(e.NewContext(httptest.NewRequest(http.MethodGet, "/getAll", nil), rec)))
+		return rec.Body.String()
+	}
+
+	first := get()
+	require.Equal(t, first, get())
+	require.Equal(t, 1, calls)
+
+	// a refreshed catalog must not be answered from the old snapshot's body
+	version = "v2"
+	require.NotEqual(t, first, get())
+	require.Equal(t, 2, calls)
+}
+
+func TestFilteredBeers_InvalidQuery(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			return beer.BeerPage{}, q.Validate()
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+	req := httptest.NewRequest(http.MethodGet, "/getFiltered?year=-5", nil)
+	rec := httptest.NewRecorder()
+
+	err := h.FilteredBeers(e.NewContext(req, rec))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.Code)
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+}
+
+func TestGetBeer_NotFound(t *testing.T) {
+	e := setupEcho()
+	h := beer.NewHandler(&mockService{}, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/beer/42", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("42")
+
+	err := h.GetBeer(c)
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusNotFound, httpErr.Code)
+}
+
+func TestBeersByIDs(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{
+		GetBeersByIDsFunc: func(ids []int) ([]backendbeer.BeerResponse, error) {
+			require.Equal(t, []int{1, 7, 42}, ids)
+			return []backendbeer.BeerResponse{{ID: 1}, {ID: 7}}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/beer?ids=1,7,42", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.BeersByIDs(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/beer?ids=1,x", nil)
+	err := h.BeersByIDs(e.NewContext(req, httptest.NewRecorder()))
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	require.Equal(t, http.StatusBadRequest, httpErr.Code)
+}
+
+func TestFilteredBeers_RangeParams(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/getFiltered?abv_gte=5&abv_lt=7.5&brewed_after=2016-01&brewed_until=2020-06", nil)
+	require.NoError(t, h.FilteredBeers(e.NewContext(req, httptest.NewRecorder())))
+	require.Equal(t, beer.Range[float64]{
+		Lower: beer.Bound[float64]{Value: 5, Inclusive: true, Set: true},
+		Upper: beer.Bound[float64]{Value: 7.5, Set: true},
+	}, svc.LastQuery.Filters.ABV)
+	require.Equal(t, beer.Range[string]{
+		Lower: beer.Bound[string]{Value: "2016-01", Set: true},
+		Upper: beer.Bound[string]{Value: "2020-06", Inclusive: true, Set: true},
+	}, svc.LastQuery.Filters.Brewed)
+
+	req = httptest.NewRequest(http.MethodGet, "/getFiltered?abv_gt=abc&abv_lt=1&abv_lte=2", nil)
+	err := h.FilteredBeers(e.NewContext(req, httptest.NewRecorder()))
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Errors, 2)
+	require.Equal(t, "abv_gt", ve.Errors[0].Field)
+	require.Equal(t, "abv_lte", ve.Errors[1].Field)
+}
+
+func TestFilteredBeers_RejectsNonFiniteABV(t *testing.T) {
+	e := setupEcho()
+	h := beer.NewHandler(&mockService{}, nil, &config.Configuration{})
+
+	for _, v := range []string{"NaN", "Inf", "-Inf", "1e400"} {
+		req := httptest.NewRequest(http.MethodGet, "/getFiltered?abv_gt="+v, nil)
+		err := h.FilteredBeers(e.NewContext(req, httptest.NewRecorder()))
+		var ve *beer.ValidationError
+		require.ErrorAs(t, err, &ve, v)
+		require.Equal(t, "abv_gt", ve.Errors[0].Field, v)
+	}
+}
+
+func TestFilteredBeers_FoodParams(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/getFiltered?hasFood=chicken,%20lamb&match=all", nil)
+	require.NoError(t, h.FilteredBeers(e.NewContext(req, httptest.NewRecorder())))
+	require.Equal(t, []string{"chicken", "lamb"}, svc.LastQuery.Filters.Foods)
+	require.Equal(t, beer.MatchAll, svc.LastQuery.Filters.FoodMatch)
+}
+
+func TestFilteredBeers_IngredientParams(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/getFiltered?hops=Citra&hop_attribute=aroma&malt=Extra%20Pale&yeast=1056&hops_min=1oz&malt_min=4.5kg", nil)
+	require.NoError(t, h.FilteredBeers(e.NewContext(req, httptest.NewRecorder())))
+	got := svc.LastQuery.Filters.Ingredients
+	require.Equal(t, "Citra", got.Hops)
+	require.Equal(t, "aroma", got.HopAttribute)
+	require.Equal(t, "Extra Pale", got.Malt)
+	require.Equal(t, "1056", got.Yeast)
+	require.InDelta(t, 28.349523125, got.MinHops, 1e-9)
+	require.InDelta(t, 4500, got.MinMalt, 1e-9)
+
+	req = httptest.NewRequest(http.MethodGet, "/getFiltered?hops_min=lots&malt_min=3cups", nil)
+	err := h.FilteredBeers(e.NewContext(req, httptest.NewRecorder()))
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Errors, 2)
+	require.Equal(t, "hops_min", ve.Errors[0].Field)
+	require.Equal(t, "malt_min", ve.Errors[1].Field)
+}
+
+func TestFilteredBeers_StyleParams(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{
+		GetDefaultQueryFunc: func() beer.BeerQuery {
+			return beer.BeerQuery{Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleIPA}}}
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	cases := map[string][]beer.Style{
+		"":                            {beer.StyleIPA},
+		"style=Stout,%20lager":        {beer.StyleStout, beer.StyleLager},
+		"includeIpa=false":            nil,
+		"includeIpa=false&style=sour": {beer.StyleSour},
+		"style=all":                   nil,
+	}
+	for query, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/getFiltered?"+query, nil)
+		require.NoError(t, h.FilteredBeers(e.NewContext(req, httptest.NewRecorder())), query)
+		require.Equal(t, want, svc.LastQuery.Filters.Styles, query)
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"errors"
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/beer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngredientFilter(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter beer.IngredientFilter
+		want   []int
+	}{
+		{"hop name", beer.IngredientFilter{Hops: "citra"}, []int{1, 2}},
+		{"hop attribute applies to the same hop", beer.IngredientFilter{Hops: "Citra", HopAttribute: "aroma"}, []int{1}},
+		{"hop attribute spelling", beer.IngredientFilter{HopAttribute: "flavor"}, []int{3}},
+		{"hop amount in other units", beer.IngredientFilter{MinHops: 50}, []int{3}},
+		{"malt", beer.IngredientFilter{Malt: "extra pale"}, []int{1, 3}},
+		{"malt amount", beer.IngredientFilter{Malt: "Extra Pale", MinMalt: 4500}, []int{1}},
+		{"yeast", beer.IngredientFilter{Yeast: "1056"}, []int{1, 3}},
+		{"combined", beer.IngredientFilter{Hops: "citra", Yeast: "saison"}, []int{2}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := beer.BeerQuery{Filters: beer.BeerFilter{Ingredients: tc.filter}}.Query()
+			require.Equal(t, tc.want, ids(q.Run(ingredientCatalog())))
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for in, want := range map[string]float64{"20": 20, "20g": 20, "0.5 kg": 500, "2oz": 56.69904625, "1 LB": 453.59237} {
+		got, err := beer.ParseAmount(in)
+		require.NoError(t, err, in)
+		require.InDelta(t, want, got, 1e-9, in)
+	}
+
+	_, err := beer.ParseAmount("20 cups")
+	require.Error(t, err)
+	_, err = beer.ParseAmount("kg")
+	require.Error(t, err)
+}
+
+func TestIngredientFilter_Validate(t *testing.T) {
+	err := beer.BeerQuery{Filters: beer.BeerFilter{Ingredients: beer.IngredientFilter{HopAttribute: "sweet", MinMalt: -1}}}.Validate()
+
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Errors, 2)
+	require.Equal(t, "hop_attribute", ve.Errors[0].Field)
+	require.Equal(t, "malt_min", ve.Errors[1].Field)
+}
+
+func TestBeerFilter_Request(t *testing.T) {
+	f := beer.BeerFilter{
+		Foods:  []string{"fish"},
+		Brewed: beer.Range[string]{Lower: beer.Bound[string]{Value: "2016-01", Set: true}},
+		Ingredients: beer.IngredientFilter{
+			Hops: "Citra", HopAttribute: "Flavor", MinHops: 20, Malt: "Extra Pale", Yeast: "1056",
+		},
+	}
+
+	require.Equal(t, backendbeer.BeerRequest{
+		Yeast:        "1056",
+		BrewedAfter:  "2016-01",
+		Hops:         "Citra",
+		HopAttribute: "flavour",
+		MinHops:      20,
+		Malt:         "Extra Pale",
+		Food:         "fish",
+	}, f.Request())
+}
+
+func TestGetFilteredBeers_PushesRequestToFilteringClient(t *testing.T) {
+	client := &filteringMockClient{
+		mockClient: *catalogClient(ingredientCatalog()),
+		// beer 3 does not match locally and 99 is not in the snapshot
+		FilterBeersFunc: func(backendbeer.BeerRequest) ([]backendbeer.BeerResponse, error) {
+			return []backendbeer.BeerResponse{{ID: 99}, {ID: 2}, {ID: 3}}, nil
+		},
+	}
+	svc := newTestService(client, newTestConfig())
+
+	page, err := svc.GetFilteredBeers(beer.BeerQuery{Filters: beer.BeerFilter{Ingredients: beer.IngredientFilter{Hops: "citra"}}})
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, ids(page.Beers))
+	require.Equal(t, []backendbeer.BeerRequest{{Hops: "citra"}}, client.Requests)
+
+	// without criteria the upstream is not asked
+	_, err = svc.GetFilteredBeers(beer.BeerQuery{})
+	require.NoError(t, err)
+	require.Len(t, client.Requests, 1)
+}
+
+func TestGetFilteredBeers_FilteringClientErrorUsesSnapshot(t *testing.T) {
+	client := &filteringMockClient{
+		mockClient: *catalogClient(ingredientCatalog()),
+		FilterBeersFunc: func(backendbeer.BeerRequest) ([]backendbeer.BeerResponse, error) {
+			return nil, errors.New("upstream unavailable")
+		},
+	}
+	svc := newTestService(client, newTestConfig())
+
+	page, err := svc.GetFilteredBeers(beer.BeerQuery{Filters: beer.BeerFilter{Ingredients: beer.IngredientFilter{Hops: "citra"}}})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, ids(page.Beers))
+}
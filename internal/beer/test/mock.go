@@ -6,31 +6,133 @@ import (
 )
 
 type mockService struct {
-	GetAllBeersFunc       func() ([]backendbeer.BeerResponse, error)
-	GetFilteredBeersFunc  func(filters string) ([]backendbeer.BeerResponse, error)
-	GetDefaultFiltersFunc func() beer.BeerFilter
+	GetAllBeersFunc      func(page beer.Page) (beer.BeerPage, error)
+	GetFilteredBeersFunc func(q beer.BeerQuery) (beer.BeerPage, error)
+	GetDefaultQueryFunc  func() beer.BeerQuery
+	GetBeerFunc          func(id int) (backendbeer.BeerResponse, error)
+	GetBeersByIDsFunc    func(ids []int) ([]backendbeer.BeerResponse, error)
+	SearchBeersFunc      func(text string, page beer.Page, facets []string) (beer.SearchPage, error)
+	GetStatsFunc         func(q beer.BeerQuery, top int) (beer.BeerStats, error)
+	GetSimilarBeersFunc  func(id int, limit int) ([]beer.SimilarBeer, error)
+	GetFoodsFunc         func() ([]beer.ValueCount, error)
+	GetBeersByFoodFunc   func(food string, page beer.Page) (beer.BeerPage, error)
+	CompareBeersFunc     func(ids []int) (beer.BeerComparison, error)
+	SnapshotFunc         func() (beer.SnapshotInfo, error)
 
-	LastFiltersString string
+	LastQuery beer.BeerQuery
 }
 
-func (m *mockService) GetAllBeers() ([]backendbeer.BeerResponse, error) {
+func (m *mockService) GetAllBeers(page beer.Page) (beer.BeerPage, error) {
 	if m.GetAllBeersFunc != nil {
-		return m.GetAllBeersFunc()
+		return m.GetAllBeersFunc(page)
 	}
-	return nil, nil
+	return beer.BeerPage{}, nil
 }
 
-func (m *mockService) GetFilteredBeers(filters string) ([]backendbeer.BeerResponse, error) {
-	m.LastFiltersString = filters
+func (m *mockService) GetFilteredBeers(q beer.BeerQuery) (beer.BeerPage, error) {
+	m.LastQuery = q
 	if m.GetFilteredBeersFunc != nil {
-		return m.GetFilteredBeersFunc(filters)
+		return m.GetFilteredBeersFunc(q)
+	}
+	return beer.BeerPage{}, nil
+}
+
+func (m *mockService) GetDefaultQuery() beer.BeerQuery {
+	if m.GetDefaultQueryFunc != nil {
+		return m.GetDefaultQueryFunc()
+	}
+	return beer.BeerQuery{}
+}
+
+func (m *mockService) GetBeer(id int) (backendbeer.BeerResponse, error) {
+	if m.GetBeerFunc != nil {
+		return m.GetBeerFunc(id)
+	}
+	return backendbeer.BeerResponse{}, beer.ErrBeerNotFound
+}
+
+func (m *mockService) GetBeersByIDs(ids []int) ([]backendbeer.BeerResponse, error) {
+	if m.GetBeersByIDsFunc != nil {
+		return m.GetBeersByIDsFunc(ids)
+	}
+	return nil, nil
+}
+
+func (m *mockService) SearchBeers(text string, page beer.Page, facets []string) (beer.SearchPage, error) {
+	if m.SearchBeersFunc != nil {
+		return m.SearchBeersFunc(text, page, facets)
+	}
+	return beer.SearchPage{}, nil
+}
+
+func (m *mockService) GetStats(q beer.BeerQuery, top int) (beer.BeerStats, error) {
+	m.LastQuery = q
+	if m.GetStatsFunc != nil {
+		return m.GetStatsFunc(q, top)
+	}
+	return beer.BeerStats{}, nil
+}
+
+func (m *mockService) GetSimilarBeers(id int, limit int) ([]beer.SimilarBeer, error) {
+	if m.GetSimilarBeersFunc != nil {
+		return m.GetSimilarBeersFunc(id, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockService) GetFoods() ([]beer.ValueCount, error) {
+	if m.GetFoodsFunc != nil {
+		return m.GetFoodsFunc()
+	}
+	return nil, nil
+}
+
+func (m *mockService) GetBeersByFood(food string, page beer.Page) (beer.BeerPage, error) {
+	if m.GetBeersByFoodFunc != nil {
+		return m.GetBeersByFoodFunc(food, page)
+	}
+	return beer.BeerPage{}, nil
+}
+
+func (m *mockService) CompareBeers(ids []int) (beer.BeerComparison, error) {
+	if m.CompareBeersFunc != nil {
+		return m.CompareBeersFunc(ids)
+	}
+	return beer.BeerComparison{}, nil
+}
+
+func (m *mockService) Snapshot() (beer.SnapshotInfo, error) {
+	if m.SnapshotFunc != nil {
+		return m.SnapshotFunc()
+	}
+	return beer.SnapshotInfo{}, nil
+}
+
+type mockClient struct {
+	ListBeersFunc func() ([]backendbeer.BeerResponse, error)
+
+	Calls int
+}
+
+func (m *mockClient) ListBeers() ([]backendbeer.BeerResponse, error) {
+	m.Calls++
+	if m.ListBeersFunc != nil {
+		return m.ListBeersFunc()
 	}
 	return nil, nil
 }
 
-func (m *mockService) GetDefaultFilters() beer.BeerFilter {
-	if m.GetDefaultFiltersFunc != nil {
-		return m.GetDefaultFiltersFunc()
+type filteringMockClient struct {
+	mockClient
+	FilterBeersFunc func(req backendbeer.BeerRequest) ([]backendbeer.BeerResponse, error)
+
+	Requests []backendbeer.BeerRequest
+}
+
+func (m *filteringMockClient) FilterBeers(req backendbeer.BeerRequest) ([]backendbeer.BeerResponse, error) {
+	m.Requests = append(m.Requests, req)
+	if m.FilterBeersFunc != nil {
+		return m.FilterBeersFunc(req)
 	}
-	return beer.BeerFilter{}
+	return nil, nil
 }
@@ -0,0 +1,122 @@
+package test
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAllBeers_LimitOffset(t *testing.T) {
+	client := catalogClient(catalogOf(5))
+	svc := newTestService(client, newTestConfig())
+
+	page, err := svc.GetAllBeers(beer.Page{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 3}, ids(page.Beers))
+	require.Equal(t, 5, page.Total)
+	require.NotEmpty(t, page.Next)
+	require.NotEmpty(t, page.Prev)
+
+	page, err = svc.GetAllBeers(beer.Page{Offset: 10})
+	require.NoError(t, err)
+	require.Empty(t, page.Beers)
+	require.Equal(t, 5, page.Total)
+}
+
+func TestGetAllBeers_CursorWalk(t *testing.T) {
+	client := catalogClient(catalogOf(5))
+	svc := newTestService(client, newTestConfig())
+
+	var seen []int
+	page, err := svc.GetAllBeers(beer.Page{Limit: 2})
+	require.NoError(t, err)
+	first := page.Next
+	for {
+		seen = append(seen, ids(page.Beers)...)
+		if page.Next == "" {
+			break
+		}
+		page, err = svc.GetAllBeers(beer.Page{Cursor: page.Next})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []int{1, 2, 3, 4, 5}, seen)
+
+	// the same cursor points at the same beers while the snapshot is unchanged
+	again, err := svc.GetAllBeers(beer.Page{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, first, again.Next)
+
+	page, err = svc.GetAllBeers(beer.Page{Cursor: first})
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 4}, ids(page.Beers))
+}
+
+func TestGetAllBeers_CursorFromOtherSnapshot(t *testing.T) {
+	n := 5
+	client := &mockClient{ListBeersFunc: func() ([]backendbeer.BeerResponse, error) { return catalogOf(n), nil }}
+	cfg := newTestConfig()
+	cfg.Cache.TTL = 10 * time.Millisecond
+	svc := newTestService(client, cfg)
+
+	page, err := svc.GetAllBeers(beer.Page{Limit: 2})
+	require.NoError(t, err)
+
+	n = 6
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = svc.GetAllBeers(beer.Page{Cursor: page.Next})
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+
+	_, err = svc.GetAllBeers(beer.Page{Cursor: "not-a-cursor"})
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+}
+
+func TestGetFilteredBeers_CursorFromOtherQuery(t *testing.T) {
+	client := catalogClient(catalogOf(5))
+	svc := newTestService(client, newTestConfig())
+
+	page, err := svc.GetFilteredBeers(beer.BeerQuery{Page: beer.Page{Limit: 2}})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.Next)
+
+	page, err = svc.GetFilteredBeers(beer.BeerQuery{Page: beer.Page{Cursor: page.Next}})
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 4}, ids(page.Beers))
+
+	other := beer.BeerQuery{Filters: beer.BeerFilter{Name: "beer"}, Page: beer.Page{Cursor: page.Prev}}
+	_, err = svc.GetFilteredBeers(other)
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+
+	_, err = svc.GetAllBeers(beer.Page{Cursor: page.Prev})
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+}
+
+func TestListAllBeers_LinkHeaders(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{
+		GetAllBeersFunc: func(page beer.Page) (beer.BeerPage, error) {
+			require.Equal(t, beer.Page{Limit: 2, Offset: 2}, page)
+			return beer.BeerPage{Beers: catalogOf(2), Total: 5, Offset: 2, Limit: 2, Next: "n", Prev: "p"}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/beer/getAll?limit=2&offset=2", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ListAllBeers(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "5", rec.Header().Get("X-Total-Count"))
+
+	links := strings.Split(rec.Header().Get("Link"), ", ")
+	require.Equal(t, []string{
+		`<http://example.com/beer/getAll?cursor=n&limit=2>; rel="next"`,
+		`<http://example.com/beer/getAll?cursor=p&limit=2>; rel="prev"`,
+	}, links)
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"encoding/json"
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"interview-go/internal/cache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAllBeers_NestedFields(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{
+		GetAllBeersFunc: func(page beer.Page) (beer.BeerPage, error) {
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{projectionBeer()}, Total: 1}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/getAll?fields=id,name,ingredients.hops.name,ingredients.yeast", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.ListAllBeers(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `[{
+		"id": 1,
+		"name": "Ruby IPA",
+		"ingredients": {"hops": [{"name": "Cascade"}, {"name": "Citra"}], "yeast": "Wyeast 1056"}
+	}]`, rec.Body.String())
+
+	// a parent path keeps the whole value
+	req = httptest.NewRequest(http.MethodGet, "/getAll?fields=ingredients.malt.name,ingredients.malt", nil)
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.ListAllBeers(e.NewContext(req, rec)))
+	require.JSONEq(t, `[{"ingredients": {"malt": [{"name": "Extra Pale", "amount": {"value": 5, "unit": "kilograms"}}]}}]`, rec.Body.String())
+}
+
+func TestListAllBeers_InvalidFields(t *testing.T) {
+	e := setupEcho()
+	h := beer.NewHandler(&mockService{}, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/getAll?fields=id,ingredients.hops.colour,abv.value,name.", nil)
+	err := h.ListAllBeers(e.NewContext(req, httptest.NewRecorder()))
+
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Errors, 3)
+	for _, fe := range ve.Errors {
+		require.Equal(t, "fields", fe.Field)
+	}
+}
+
+func TestFilteredBeers_ProjectionsCachedSeparately(t *testing.T) {
+	e := setupEcho()
+
+	calls := 0
+	svc := &mockService{
+		GetDefaultQueryFunc: func() beer.BeerQuery { return beer.BeerQuery{} },
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			calls++
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{projectionBeer()}, Total: 1}, nil
+		},
+	}
+	cfg := &config.Configuration{}
+	cfg.Cache.TTL = time.Minute
+	cfg.Cache.ClearTicker = time.Minute
+	h := beer.NewHandler(svc, cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker), cfg)
+
+	get := func(query string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, "/getFiltered?"+query, nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.FilteredBeers(e.NewContext(req, rec)))
+		var got []map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		return got[0]
+	}
+
+	require.Len(t, get("fields=id,name"), 2)
+	require.Len(t, get("fields=name,id"), 2)
+	require.Equal(t, 1, calls, "the same selection in another order reuses the encoding")
+
+	require.Len(t, get("fields=id"), 1)
+	require.Contains(t, get(""), "description")
+	require.Equal(t, 3, calls)
+}
+
+func TestSearchBeers_FieldsKeepScore(t *testing.T) {
+	e := setupEcho()
+	svc := &mockService{
+		SearchBeersFunc: func(text string, page beer.Page, facets []string) (beer.SearchPage, error) {
+			return beer.SearchPage{Hits: []beer.SearchHit{{BeerResponse: projectionBeer(), Score: 1.5}}, Total: 1}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=ruby&fields=name", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.SearchBeers(e.NewContext(req, rec)))
+	require.JSONEq(t, `[{"name": "Ruby IPA", "score": 1.5}]`, rec.Body.String())
+}
@@ -0,0 +1,219 @@
+package test
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/beer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeerFilterQuery_DefaultFilteringAndSorting(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 1, Name: "Ruby IPA", FirstBrewed: "2016-01", ABV: 6.0, FoodPairing: []string{"wolf", "steak"}},
+		{ID: 2, Name: "Lager", FirstBrewed: "2017-05", ABV: 4.5, FoodPairing: []string{"wolf"}},
+		{ID: 3, Name: "Pale Ale", FirstBrewed: "2018-03", ABV: 5.2, FoodPairing: []string{"pizza"}},
+		{ID: 4, Name: "Imperial IPA", FirstBrewed: "2014-12", ABV: 8.5, FoodPairing: []string{"wolf"}},
+	}
+
+	q := beer.BeerQuery{
+		Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleIPA}, Year: 2015, Foods: []string{"wolf"}},
+		Sort:    []beer.SortKey{{Field: "abv"}},
+	}.Query()
+	require.Equal(t, []int{1}, ids(q.Run(beers)))
+}
+
+func TestBeerFilterQuery_Overrides(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 10, Name: "Dark Lager", FirstBrewed: "2020-01", ABV: 7.2, FoodPairing: []string{"fish"}},
+		{ID: 11, Name: "Summer Ale", FirstBrewed: "2021-06", ABV: 4.0, FoodPairing: []string{"fish"}},
+		{ID: 12, Name: "Tropical IPA", FirstBrewed: "2022-03", ABV: 5.5, FoodPairing: []string{"fish"}},
+	}
+
+	q := beer.BeerQuery{
+		Filters: beer.BeerFilter{Year: 2020, Foods: []string{"fish"}},
+		Sort:    []beer.SortKey{{Field: "abv", Desc: true}},
+	}.Query()
+	require.Equal(t, []int{12, 11}, ids(q.Run(beers))) // higher ABV first due to desc
+}
+
+func TestBeerFilterQuery_UnknownFirstBrewed(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 1, Name: "Mystery Ale", FirstBrewed: ""},
+		{ID: 2, Name: "Old Ale", FirstBrewed: "2014-01"},
+		{ID: 3, Name: "New Ale", FirstBrewed: "2019-01"},
+	}
+
+	// without a year no beer is dropped for its brew date
+	require.Equal(t, []int{1, 2, 3}, ids(beer.BeerQuery{Filters: beer.BeerFilter{Name: "ale"}}.Query().Run(beers)))
+
+	// a year needs a known brew date
+	q := beer.BeerQuery{Filters: beer.BeerFilter{Year: 2015}}.Query()
+	require.Equal(t, []int{3}, ids(q.Run(beers)))
+}
+
+func TestQuery_Composition(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 1, Name: "Hazy IPA", ABV: 6.0, FoodPairing: []string{"chicken"}},
+		{ID: 2, Name: "Stout", ABV: 6.0, FoodPairing: []string{"lamb"}},
+		{ID: 3, Name: "Pils", ABV: 4.5, FoodPairing: []string{"fish"}},
+	}
+
+	q := beer.Query{
+		Filter: beer.And(
+			beer.Or(beer.PairsWith("chicken"), beer.PairsWith("lamb")),
+			beer.Not(beer.NameContains("pils")),
+		),
+		Sort: beer.Comparator(beer.ByABV).Reverse().Then(func(a, b backendbeer.BeerResponse) int {
+			return b.ID - a.ID
+		}),
+	}
+	require.Equal(t, []int{2, 1}, ids(q.Run(beers)))
+}
+
+func TestQuery_VisitSeesMatchesInFilterPass(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 1, ABV: 4.5},
+		{ID: 3, ABV: 6},
+		{ID: 2, ABV: 8},
+	}
+
+	var visited []int
+	q := beer.Query{
+		Filter: func(b backendbeer.BeerResponse) bool { return b.ABV > 5 },
+		Sort:   beer.Comparator(beer.ByABV).Reverse(),
+		Visit:  func(b backendbeer.BeerResponse) { visited = append(visited, b.ID) },
+	}
+	require.Equal(t, []int{2, 3}, ids(q.Run(beers)))
+	// matches are visited in input order, before sorting
+	require.Equal(t, []int{3, 2}, visited)
+}
+
+func TestBeerQuery_Validate(t *testing.T) {
+	require.NoError(t, beer.BeerQuery{Sort: []beer.SortKey{{Field: "abv"}}, Fields: []string{"id", "name"}}.Validate())
+
+	err := beer.BeerQuery{
+		Filters: beer.BeerFilter{Year: -1},
+		Sort:    []beer.SortKey{{Field: "color"}},
+		Page:    beer.Page{Limit: beer.MaxPageLimit + 1, Offset: -1},
+		Fields:  []string{"colour"},
+	}.Validate()
+
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+
+	fields := make([]string, 0, len(ve.Errors))
+	for _, fe := range ve.Errors {
+		fields = append(fields, fe.Field)
+	}
+	require.Equal(t, []string{"year", "sort", "limit", "offset", "fields"}, fields)
+}
+
+func TestParseSort(t *testing.T) {
+	keys, err := beer.ParseSort("-abv, name,first_brewed,id")
+	require.NoError(t, err)
+	require.Equal(t, []beer.SortKey{
+		{Field: "abv", Desc: true},
+		{Field: "name"},
+		{Field: "first_brewed"},
+		{Field: "id"},
+	}, keys)
+	require.Equal(t, "-abv,name,first_brewed,id", beer.FormatSort(keys))
+
+	_, err = beer.ParseSort("abv,,name")
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+}
+
+func TestBeerQuery_MultiFieldSort(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 4, Name: "b", ABV: 5, FirstBrewed: "2020-01"},
+		{ID: 3, Name: "a", ABV: 5, FirstBrewed: "2020-01"},
+		{ID: 2, Name: "B", ABV: 5, FirstBrewed: "2019-06"},
+		{ID: 1, Name: "z", ABV: 7, FirstBrewed: "2010-01"},
+	}
+
+	keys, err := beer.ParseSort("-abv,name")
+	require.NoError(t, err)
+	q := beer.BeerQuery{Sort: keys}
+	require.NoError(t, q.Validate())
+	// names compare case-insensitively, the "b" tie is broken by id
+	require.Equal(t, []int{1, 3, 2, 4}, ids(q.Query().Run(beers)))
+
+	q = beer.BeerQuery{Sort: []beer.SortKey{{Field: "first_brewed", Desc: true}}}
+	require.Equal(t, []int{3, 4, 2, 1}, ids(q.Query().Run(beers)))
+}
+
+func TestBeerQuery_SortByIBU(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 1, IBU: 35, FirstBrewed: "2020-01"},
+		{ID: 2, IBU: 70, FirstBrewed: "2020-01"},
+		{ID: 3, IBU: 8, FirstBrewed: "2020-01"},
+	}
+
+	keys, err := beer.ParseSort("-ibu")
+	require.NoError(t, err)
+	q := beer.BeerQuery{Sort: keys}
+	require.NoError(t, q.Validate())
+	require.Equal(t, []int{2, 1, 3}, ids(q.Query().Run(beers)))
+}
+
+func TestBeerQuery_UnknownOrDuplicateSortField(t *testing.T) {
+	for _, s := range []string{"colour", "abv,-abv", "ingredients"} {
+		keys, err := beer.ParseSort(s)
+		require.NoError(t, err, s)
+		require.ErrorIs(t, beer.BeerQuery{Sort: keys}.Validate(), beer.ErrInvalidQuery, s)
+	}
+}
+
+func TestBeerFilter_RangeFilters(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 1, ABV: 4.5, FirstBrewed: "2015-06"},
+		{ID: 2, ABV: 5.0, FirstBrewed: "2016-01"},
+		{ID: 3, ABV: 6.5, FirstBrewed: "2016-02"},
+		{ID: 4, ABV: 7.0, FirstBrewed: "2018-12"},
+	}
+
+	run := func(f beer.BeerFilter) []int {
+		q := beer.BeerQuery{Filters: f}
+		require.NoError(t, q.Validate())
+		return ids(q.Query().Run(beers))
+	}
+
+	abv := beer.Range[float64]{
+		Lower: beer.Bound[float64]{Value: 5, Set: true},
+		Upper: beer.Bound[float64]{Value: 7, Set: true},
+	}
+	require.Equal(t, []int{3}, run(beer.BeerFilter{ABV: abv}))
+
+	abv.Lower.Inclusive, abv.Upper.Inclusive = true, true
+	require.Equal(t, []int{2, 3, 4}, run(beer.BeerFilter{ABV: abv}))
+
+	brewed := beer.Range[string]{
+		Lower: beer.Bound[string]{Value: "2016-01", Set: true},
+		Upper: beer.Bound[string]{Value: "2018-12", Set: true},
+	}
+	require.Equal(t, []int{3}, run(beer.BeerFilter{Brewed: brewed}))
+
+	brewed.Lower.Inclusive = true
+	require.Equal(t, []int{2, 3}, run(beer.BeerFilter{Brewed: brewed}))
+}
+
+func TestBeerFilter_InvalidRanges(t *testing.T) {
+	err := beer.BeerQuery{Filters: beer.BeerFilter{
+		ABV: beer.Range[float64]{
+			Lower: beer.Bound[float64]{Value: 8, Set: true},
+			Upper: beer.Bound[float64]{Value: 5, Set: true},
+		},
+		Brewed: beer.Range[string]{
+			Lower: beer.Bound[string]{Value: "2016-13", Set: true},
+		},
+	}}.Validate()
+
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, []beer.FieldError{
+		{Field: "abv", Message: "lower bound 8 is not below upper bound 5"},
+		{Field: "brewed_after", Message: `must be a month in yyyy-mm format, got "2016-13"`},
+	}, ve.Errors)
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func postSearch(t *testing.T, h beer.HTTPHandler, target, body string) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	return rec, h.AdvancedSearch(setupEcho().NewContext(req, rec))
+}
+
+func TestAdvancedSearch_BuildsQuery(t *testing.T) {
+	svc := &mockService{
+		GetFilteredBeersFunc: func(q beer.BeerQuery) (beer.BeerPage, error) {
+			return beer.BeerPage{Beers: []backendbeer.BeerResponse{{ID: 1, Name: "Ruby IPA", ABV: 6}}, Total: 1}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	rec, err := postSearch(t, h, "/search", `{
+		"beerName": "ruby",
+		"hops": "Citra",
+		"hopAttribute": "aroma",
+		"minHops": 20,
+		"malt": "Extra Pale",
+		"yeast": "1056",
+		"food": "chicken",
+		"brewedAfter": "2015-01",
+		"brewedBefore": "2020-12",
+		"sort": "-abv,name",
+		"limit": 10,
+		"offset": 20,
+		"fields": ["id", "name", "ingredients.hops.name"]
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `[{"id": 1, "name": "Ruby IPA", "ingredients": {"hops": null}}]`, rec.Body.String())
+	require.Equal(t, "1", rec.Header().Get("X-Total-Count"))
+
+	require.Equal(t, beer.BeerQuery{
+		Filters: beer.BeerFilter{
+			Name:  "ruby",
+			Foods: []string{"chicken"},
+			Brewed: beer.Range[string]{
+				Lower: beer.Bound[string]{Value: "2015-01", Set: true},
+				Upper: beer.Bound[string]{Value: "2020-12", Set: true},
+			},
+			Ingredients: beer.IngredientFilter{
+				Hops: "Citra", HopAttribute: "aroma", MinHops: 20, Malt: "Extra Pale", Yeast: "1056",
+			},
+		},
+		Sort:   []beer.SortKey{{Field: "abv", Desc: true}, {Field: "name"}},
+		Page:   beer.Page{Limit: 10, Offset: 20},
+		Fields: []string{"id", "name", "ingredients.hops.name"},
+	}, svc.LastQuery)
+}
+
+func TestAdvancedSearch_CursorFromLink(t *testing.T) {
+	svc := &mockService{}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	client := catalogClient(catalogOf(5))
+	first, err := newTestService(client, newTestConfig()).GetAllBeers(beer.Page{Limit: 2})
+	require.NoError(t, err)
+	cursor := first.Next
+
+	_, err = postSearch(t, h, "/search?cursor="+cursor, `{"cursor": "old"}`)
+	require.NoError(t, err)
+	require.Equal(t, cursor, svc.LastQuery.Page.Cursor)
+}
+
+func TestAdvancedSearch_FieldErrors(t *testing.T) {
+	h := beer.NewHandler(&mockService{}, nil, &config.Configuration{})
+
+	cases := []struct {
+		body   string
+		fields []string
+	}{
+		{`{"brewedAfter": "2015", "hopAttribute": "sweet", "minMalt": -1, "limit": 1000}`,
+			[]string{"brewedAfter", "hopAttribute", "minMalt", "limit"}},
+		{`{"sort": "colour", "fields": ["id", "ingredients.hops.colour"]}`, []string{"sort", "fields[1]"}},
+		{`{"limit": "ten"}`, []string{"limit"}},
+		{`{"beer_name": "x"}`, []string{"beer_name"}},
+		{``, []string{"body"}},
+		{`{"limit": 1`, []string{"body"}},
+		{`{"limit": 1} {"limit": 2}`, []string{"body"}},
+		{`{"limit": 1} trailing`, []string{"body"}},
+	}
+
+	for _, tc := range cases {
+		_, err := postSearch(t, h, "/search", tc.body)
+
+		var ve *beer.ValidationError
+		require.ErrorAs(t, err, &ve, tc.body)
+		var got []string
+		for _, fe := range ve.Errors {
+			got = append(got, fe.Field)
+		}
+		require.Equal(t, tc.fields, got, tc.body)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		require.Equal(t, http.StatusBadRequest, httpErr.Code)
+	}
+}
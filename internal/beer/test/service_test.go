@@ -0,0 +1,137 @@
+package test
+
+import (
+	"errors"
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/beer"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFilteredBeers_NegativeCacheUpstreamError(t *testing.T) {
+	upstreamErr := errors.New("upstream unavailable")
+	client := &mockClient{
+		ListBeersFunc: func() ([]backendbeer.BeerResponse, error) { return nil, upstreamErr },
+	}
+	svc := newTestService(client, newTestConfig())
+
+	_, err := svc.GetFilteredBeers(beer.BeerQuery{})
+	require.ErrorIs(t, err, upstreamErr)
+
+	_, err = svc.GetFilteredBeers(beer.BeerQuery{})
+	require.ErrorIs(t, err, upstreamErr)
+	require.Equal(t, 1, client.Calls)
+}
+
+func TestGetFilteredBeers_NegativeCacheEmptyResult(t *testing.T) {
+	client := &mockClient{
+		ListBeersFunc: func() ([]backendbeer.BeerResponse, error) { return []backendbeer.BeerResponse{}, nil },
+	}
+	svc := newTestService(client, newTestConfig())
+
+	for i := 0; i < 3; i++ {
+		got, err := svc.GetFilteredBeers(beer.BeerQuery{})
+		require.NoError(t, err)
+		require.Empty(t, got.Beers)
+	}
+	require.Equal(t, 1, client.Calls)
+}
+
+func TestGetFilteredBeers_NegativeEntryExpires(t *testing.T) {
+	client := &mockClient{
+		ListBeersFunc: func() ([]backendbeer.BeerResponse, error) { return nil, errors.New("boom") },
+	}
+	cfg := newTestConfig()
+	cfg.Cache.NegativeTTL = time.Millisecond
+	svc := newTestService(client, cfg)
+
+	_, err := svc.GetFilteredBeers(beer.BeerQuery{})
+	require.Error(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+	_, err = svc.GetFilteredBeers(beer.BeerQuery{})
+	require.Error(t, err)
+	require.Equal(t, 2, client.Calls)
+}
+
+func TestGetFilteredBeers_RunsQueryOverCachedCatalog(t *testing.T) {
+	client := &mockClient{
+		ListBeersFunc: func() ([]backendbeer.BeerResponse, error) {
+			return []backendbeer.BeerResponse{
+				{ID: 1, Name: "Ruby IPA", FirstBrewed: "2016-01", ABV: 6.0, FoodPairing: []string{"wolf"}},
+				{ID: 2, Name: "Hazy IPA", FirstBrewed: "2019-01", ABV: 5.0, FoodPairing: []string{"wolf"}},
+				{ID: 3, Name: "Lager", FirstBrewed: "2020-01", ABV: 4.5, FoodPairing: []string{"fish"}},
+			}, nil
+		},
+	}
+	svc := newTestService(client, newTestConfig())
+
+	got, err := svc.GetFilteredBeers(beer.BeerQuery{
+		Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleIPA}, Year: 2015, Foods: []string{"wolf"}},
+		Sort:    []beer.SortKey{{Field: "abv"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 1}, ids(got.Beers))
+
+	got, err = svc.GetFilteredBeers(beer.BeerQuery{Filters: beer.BeerFilter{Year: 2015, Foods: []string{"fish"}}})
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, ids(got.Beers))
+
+	require.Equal(t, 1, client.Calls)
+}
+
+func TestGetFilteredBeers_InvalidQueryRejectedBeforeUpstream(t *testing.T) {
+	client := &mockClient{}
+	svc := newTestService(client, newTestConfig())
+
+	_, err := svc.GetFilteredBeers(beer.BeerQuery{Sort: []beer.SortKey{{Field: "color"}}})
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+	require.Equal(t, 0, client.Calls)
+}
+
+func TestGetBeer_AndBatchLookupShareSnapshot(t *testing.T) {
+	client := &mockClient{
+		ListBeersFunc: func() ([]backendbeer.BeerResponse, error) {
+			return []backendbeer.BeerResponse{{ID: 1, Name: "Ruby IPA"}, {ID: 2, Name: "Lager"}, {ID: 3, Name: "Stout"}}, nil
+		},
+	}
+	svc := newTestService(client, newTestConfig())
+
+	b, err := svc.GetBeer(2)
+	require.NoError(t, err)
+	require.Equal(t, "Lager", b.Name)
+
+	_, err = svc.GetBeer(42)
+	require.ErrorIs(t, err, beer.ErrBeerNotFound)
+
+	got, err := svc.GetBeersByIDs([]int{3, 42, 1, 3})
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 1}, ids(got))
+
+	require.Equal(t, 1, client.Calls)
+}
+
+func TestSearchBeers_RanksByRelevance(t *testing.T) {
+	client := &mockClient{
+		ListBeersFunc: func() ([]backendbeer.BeerResponse, error) {
+			return []backendbeer.BeerResponse{
+				{ID: 1, Name: "Punk IPA", Tagline: "Post Modern Classic", Description: "Citrus and tropical fruit hops."},
+				{ID: 2, Name: "Hazy Jane", Tagline: "New England IPA", Description: "Hazy, juicy and packed with citrus."},
+				{ID: 3, Name: "Black Eyed King Imp", Tagline: "Imperial Stout", Description: "Roasted coffee and dark chocolate."},
+			}, nil
+		},
+	}
+	svc := newTestService(client, newTestConfig())
+
+	page, err := svc.SearchBeers("citrus hazy", beer.Page{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	require.Equal(t, 2, page.Hits[0].ID)
+	require.Equal(t, 1, page.Hits[1].ID)
+	require.Greater(t, page.Hits[0].Score, page.Hits[1].Score)
+
+	_, err = svc.SearchBeers("  ", beer.Page{}, nil)
+	require.ErrorIs(t, err, beer.ErrInvalidQuery)
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"encoding/json"
+	backendbeer "interview-go/backend/client"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func similarService(weights func(cfg *config.Configuration)) beer.Service {
+	cfg := newTestConfig()
+	cfg.Similarity.Hops, cfg.Similarity.Malts, cfg.Similarity.ABV, cfg.Similarity.Style, cfg.Similarity.Food =
+		config.SimilarityHops, config.SimilarityMalts, config.SimilarityABV, config.SimilarityStyle, config.SimilarityFood
+	if weights != nil {
+		weights(cfg)
+	}
+	client := catalogClient(similarCatalog())
+	return newTestService(client, cfg)
+}
+
+func similarIDs(beers []beer.SimilarBeer) []int {
+	out := make([]int, 0, len(beers))
+	for _, b := range beers {
+		out = append(out, b.ID)
+	}
+	return out
+}
+
+func TestGetSimilarBeers_RanksByFeatures(t *testing.T) {
+	svc := similarService(nil)
+
+	got, err := svc.GetSimilarBeers(1, 10)
+	require.NoError(t, err)
+	// the beer itself and beers sharing nothing are left out
+	require.Equal(t, []int{2, 4, 3}, similarIDs(got))
+	require.InDelta(t, 0.3+0.15+0.18+0.2+0.15/4, got[0].Score, 0.001)
+	require.InDelta(t, 0.15+0.15+0.16, got[1].Score, 0.001)
+	require.InDelta(t, 0.08, got[2].Score, 0.001)
+
+	got, err = svc.GetSimilarBeers(1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 4}, similarIDs(got))
+
+	_, err = svc.GetSimilarBeers(99, 10)
+	require.ErrorIs(t, err, beer.ErrBeerNotFound)
+}
+
+func TestGetSimilarBeers_ConfiguredWeights(t *testing.T) {
+	svc := similarService(func(cfg *config.Configuration) {
+		cfg.Similarity.Hops, cfg.Similarity.Malts, cfg.Similarity.ABV, cfg.Similarity.Style, cfg.Similarity.Food = 1, 0, 0, 0, 0
+	})
+
+	got, err := svc.GetSimilarBeers(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 4}, similarIDs(got))
+	require.Equal(t, 1.0, got[0].Score)
+	require.Equal(t, 0.5, got[1].Score)
+}
+
+func TestSimilarBeers_Handler(t *testing.T) {
+	e := setupEcho()
+	var gotID, gotLimit int
+	svc := &mockService{
+		GetSimilarBeersFunc: func(id int, limit int) ([]beer.SimilarBeer, error) {
+			gotID, gotLimit = id, limit
+			if id == 99 {
+				return nil, beer.ErrBeerNotFound
+			}
+			return []beer.SimilarBeer{{BeerResponse: backendbeer.BeerResponse{ID: 2, Name: "Other IPA", ABV: 6.5}, Score: 0.87}}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	similar := func(id, query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/beer/"+id+"/similar"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return rec, h.SimilarBeers(c)
+	}
+
+	rec, err := similar("7", "")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 7, gotID)
+	require.Equal(t, beer.DefaultSimilarLimit, gotLimit)
+
+	rec, err = similar("7", "?limit=3&fields=name")
+	require.NoError(t, err)
+	require.Equal(t, 3, gotLimit)
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	// the score is kept next to the projected fields
+	require.Equal(t, []map[string]any{{"name": "Other IPA", "score": 0.87}}, got)
+
+	var ve *beer.ValidationError
+	_, err = similar("7", "?limit=0")
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, "limit", ve.Errors[0].Field)
+
+	_, err = similar("99", "")
+	var he *echo.HTTPError
+	require.ErrorAs(t, err, &he)
+	require.Equal(t, http.StatusNotFound, he.Code)
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"encoding/json"
+	"interview-go/config"
+	"interview-go/internal/beer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStats_AggregatesSnapshot(t *testing.T) {
+	client := catalogClient(statsCatalog())
+	svc := newTestService(client, newTestConfig())
+
+	st, err := svc.GetStats(beer.BeerQuery{}, 2)
+	require.NoError(t, err)
+
+	require.Equal(t, 4, st.Count)
+	require.Equal(t, beer.NumberStats{
+		Count: 4, Min: 4, Max: 10, Mean: 7,
+		Percentiles: map[string]float64{"p25": 5.5, "p50": 7, "p75": 8.5, "p90": 9.4, "p95": 9.7, "p99": 9.94},
+	}, st.ABV)
+	require.Equal(t, map[int]int{1995: 1, 1999: 1, 2003: 2}, st.ByYear)
+	require.Equal(t, map[string]int{"1990s": 2, "2000s": 2}, st.ByDecade)
+	// repeated mentions within a beer count once; ties sort by name
+	require.Equal(t, []beer.ValueCount{{Value: "Chicken", Count: 2}, {Value: "Lamb", Count: 2}}, st.TopFoods)
+	require.Equal(t, []beer.ValueCount{{Value: "Cascade", Count: 2}, {Value: "Citra", Count: 2}}, st.TopHops)
+	require.Equal(t, []beer.ValueCount{{Value: "Extra Pale", Count: 4}}, st.TopMalts)
+
+	st, err = svc.GetStats(beer.BeerQuery{Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleIPA}}}, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, st.Count)
+	require.Equal(t, 6.0, st.ABV.Mean)
+
+	st, err = svc.GetStats(beer.BeerQuery{Filters: beer.BeerFilter{Name: "nothing"}}, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, st.Count)
+	require.Empty(t, st.ABV.Percentiles)
+
+	require.Equal(t, 1, client.Calls)
+}
+
+func TestStats_Handler(t *testing.T) {
+	e := setupEcho()
+	var gotTop int
+	svc := &mockService{
+		GetDefaultQueryFunc: func() beer.BeerQuery {
+			return beer.BeerQuery{Filters: beer.BeerFilter{Year: 2015, Foods: []string{"wolf"}}}
+		},
+		GetStatsFunc: func(q beer.BeerQuery, top int) (beer.BeerStats, error) {
+			gotTop = top
+			return beer.BeerStats{Count: 3, ByYear: map[int]int{2001: 3}}, nil
+		},
+	}
+	h := beer.NewHandler(svc, nil, &config.Configuration{})
+
+	req := httptest.NewRequest(http.MethodGet, "/stats?style=stout&abv_gte=5&sort=name", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.Stats(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, beer.DefaultStatsTop, gotTop)
+
+	// the getFiltered defaults do not apply
+	require.Equal(t, beer.BeerQuery{Filters: beer.BeerFilter{
+		Styles: []beer.Style{beer.StyleStout},
+		ABV:    beer.Range[float64]{Lower: beer.Bound[float64]{Value: 5, Inclusive: true, Set: true}},
+	}}, svc.LastQuery)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Equal(t, map[string]any{"2001": 3.0}, got["by_year"])
+
+	req = httptest.NewRequest(http.MethodGet, "/stats?top=5", nil)
+	require.NoError(t, h.Stats(e.NewContext(req, httptest.NewRecorder())))
+	require.Equal(t, 5, gotTop)
+
+	req = httptest.NewRequest(http.MethodGet, "/stats?top=0", nil)
+	var ve *beer.ValidationError
+	require.ErrorAs(t, h.Stats(e.NewContext(req, httptest.NewRecorder())), &ve)
+	require.Equal(t, "top", ve.Errors[0].Field)
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	backendbeer "interview-go/backend/client"
+	"interview-go/internal/beer"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		beer backendbeer.BeerResponse
+		want beer.Style
+	}{
+		{backendbeer.BeerResponse{Name: "Pliny The Elder", Tagline: "India Pale Ale"}, beer.StyleIPA},
+		{backendbeer.BeerResponse{Name: "Hercules Double IPA"}, beer.StyleIPA},
+		{backendbeer.BeerResponse{Name: "Alpha King", Tagline: "English Pale Ale"}, beer.StylePaleAle},
+		{backendbeer.BeerResponse{Name: "Yeti", Tagline: "Stout"}, beer.StyleStout},
+		{backendbeer.BeerResponse{Name: "Celebrator Doppelbock"}, beer.StyleLager},
+		{backendbeer.BeerResponse{Name: "Edmund", Tagline: "European Amber Lager"}, beer.StyleLager},
+		{backendbeer.BeerResponse{Name: "Duvel", Tagline: "Belgian Strong Ale"}, beer.StyleBelgian},
+		{backendbeer.BeerResponse{Name: "Aventinus", Tagline: "German Wheat And Rye Beer"}, beer.StyleWheat},
+		{backendbeer.BeerResponse{Name: "Weihenstephaner Hefeweissbier"}, beer.StyleWheat},
+		{backendbeer.BeerResponse{Name: "Smoky", Tagline: "Smoke-flavored"}, beer.StyleSpecialty},
+		// the tagline is the declared style and outweighs the name
+		{backendbeer.BeerResponse{Name: "Dreadnaught IPA", Tagline: "Porter"}, beer.StylePorter},
+		// "ipa" only counts as a whole word
+		{backendbeer.BeerResponse{Name: "Dipa Lager"}, beer.StyleLager},
+		{backendbeer.BeerResponse{Name: "Ripacrest"}, beer.StyleOther},
+		// without keywords the numbers decide when only one style fits
+		{backendbeer.BeerResponse{Name: "Hop Bomb", ABV: 7.5, IBU: 110}, beer.StyleIPA},
+		{backendbeer.BeerResponse{Name: "Mystery", ABV: 5, IBU: 30}, beer.StyleOther},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.want, beer.Classify(tc.beer), tc.beer.Name)
+	}
+}
+
+func TestHasStyle(t *testing.T) {
+	beers := []backendbeer.BeerResponse{
+		{ID: 1, Name: "Ruby IPA", FirstBrewed: "2016-01"},
+		{ID: 2, Name: "Ripacrest", FirstBrewed: "2016-01"},
+		{ID: 3, Name: "Night", Tagline: "Stout", FirstBrewed: "2016-01"},
+		{ID: 4, Name: "Dipa Lager", FirstBrewed: "2016-01"},
+	}
+
+	q := beer.BeerQuery{Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleIPA}}}.Query()
+	require.Equal(t, []int{1}, ids(q.Run(beers)))
+
+	q = beer.BeerQuery{Filters: beer.BeerFilter{Styles: []beer.Style{beer.StyleStout, beer.StyleLager}}}.Query()
+	require.Equal(t, []int{3, 4}, ids(q.Run(beers)))
+}
+
+func TestBeerQueryValidate_UnknownStyle(t *testing.T) {
+	err := beer.BeerQuery{Filters: beer.BeerFilter{Styles: []beer.Style{"ipa", "mead"}}}.Validate()
+
+	var ve *beer.ValidationError
+	require.ErrorAs(t, err, &ve)
+	require.Len(t, ve.Errors, 1)
+	require.Equal(t, "style", ve.Errors[0].Field)
+}
@@ -9,8 +9,42 @@ type Cache interface {
 	Get(key string) (interface{}, error)
 }
 
+// Stats is a snapshot of the counters of a cache.
+type Stats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Sets   uint64 `json:"sets"`
+
+	// Compressed counts the values stored compressed, RawBytes and
+	// CompressedBytes their sizes before and after compression.
+	Compressed       uint64  `json:"compressed"`
+	RawBytes         uint64  `json:"raw_bytes"`
+	CompressedBytes  uint64  `json:"compressed_bytes"`
+	CompressionRatio float64 `json:"compression_ratio"`
+}
+
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// entryStore is implemented by the backends of this package so that
+// layers can copy entries between them without resetting their age.
+type entryStore interface {
+	getEntry(key string) (cacheData, error)
+	setEntry(key string, cd cacheData) error
+}
+
+const (
+	BackendMemory  = "memory"
+	BackendSharded = "sharded"
+	BackendDisk    = "disk"
+	BackendRedis   = "redis"
+	BackendNone    = "none"
+)
+
 var (
 	ErrCacheMiss         = errors.New("no such key in cache")
 	ErrInvalidCacheValue = errors.New("invalid cache value")
 	ErrTTLExpired        = errors.New("ttl for this key/value expired")
+	ErrUnknownBackend    = errors.New("unknown cache backend")
 )
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+)
+
+// storedEntry is the serialized form of cacheData used by the disk and
+// redis backends. Concrete value types have to be registered with
+// gob.Register by the package that stores them.
+type storedEntry struct {
+	CreatedAt time.Time
+	Value     interface{}
+}
+
+func encodeEntry(cd cacheData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(storedEntry{CreatedAt: cd.createdAt, Value: cd.value}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeEntry(data []byte) (cacheData, error) {
+	var se storedEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&se); err != nil {
+		return cacheData{}, ErrInvalidCacheValue
+	}
+	return cacheData{createdAt: se.CreatedAt, value: se.Value}, nil
+}
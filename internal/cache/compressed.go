@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// compressedValue is what CompressedCache stores for values whose
+// serialized size is above the threshold.
+type compressedValue struct {
+	Algorithm string
+	Data      []byte
+}
+
+type gobValue struct {
+	Value interface{}
+}
+
+func init() {
+	gob.Register(compressedValue{})
+}
+
+// CompressedCache transparently gob encodes values and compresses the ones
+// larger than threshold bytes before handing them to the wrapped cache.
+// Smaller values are stored unchanged.
+type CompressedCache struct {
+	inner     Cache
+	algorithm string
+	threshold int
+
+	zenc *zstd.Encoder
+	zdec *zstd.Decoder
+
+	compressed      atomic.Uint64
+	rawBytes        atomic.Uint64
+	compressedBytes atomic.Uint64
+}
+
+func NewCompressed(inner Cache, algorithm string, threshold int) (*CompressedCache, error) {
+	cc := &CompressedCache{
+		inner:     inner,
+		algorithm: algorithm,
+		threshold: threshold,
+	}
+
+	switch algorithm {
+	case CompressionGzip:
+	case CompressionZstd:
+		var err error
+		if cc.zenc, err = zstd.NewWriter(nil); err != nil {
+			return nil, err
+		}
+		if cc.zdec, err = zstd.NewReader(nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %q", algorithm)
+	}
+
+	return cc, nil
+}
+
+// Sizer is implemented by values that know their serialized size, so
+// CompressedCache can store small ones without encoding them first.
+type Sizer interface {
+	CacheSize() int
+}
+
+// knownSize reports the size of values that can be measured without
+// encoding them.
+func knownSize(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return len(v), true
+	case string:
+		return len(v), true
+	case Sizer:
+		return v.CacheSize(), true
+	}
+	return 0, false
+}
+
+func (cc *CompressedCache) Set(key string, value interface{}) error {
+	if n, ok := knownSize(value); ok && n <= cc.threshold {
+		return cc.inner.Set(key, value)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobValue{Value: value}); err != nil {
+		return err
+	}
+	if buf.Len() <= cc.threshold {
+		return cc.inner.Set(key, value)
+	}
+
+	data, err := cc.compress(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	cc.compressed.Add(1)
+	cc.rawBytes.Add(uint64(buf.Len()))
+	cc.compressedBytes.Add(uint64(len(data)))
+
+	return cc.inner.Set(key, compressedValue{Algorithm: cc.algorithm, Data: data})
+}
+
+func (cc *CompressedCache) Get(key string) (interface{}, error) {
+	v, err := cc.inner.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	cv, ok := v.(compressedValue)
+	if !ok {
+		return v, nil
+	}
+
+	raw, err := cc.decompress(cv)
+	if err != nil {
+		return nil, err
+	}
+
+	var gv gobValue
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&gv); err != nil {
+		return nil, ErrInvalidCacheValue
+	}
+	return gv.Value, nil
+}
+
+func (cc *CompressedCache) Stats() Stats {
+	st := Stats{
+		Compressed:      cc.compressed.Load(),
+		RawBytes:        cc.rawBytes.Load(),
+		CompressedBytes: cc.compressedBytes.Load(),
+	}
+	if st.CompressedBytes > 0 {
+		st.CompressionRatio = float64(st.RawBytes) / float64(st.CompressedBytes)
+	}
+	return st
+}
+
+func (cc *CompressedCache) compress(raw []byte) ([]byte, error) {
+	if cc.algorithm == CompressionZstd {
+		return cc.zenc.EncodeAll(raw, make([]byte, 0, len(raw)/4)), nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (cc *CompressedCache) decompress(cv compressedValue) ([]byte, error) {
+	switch cv.Algorithm {
+	case CompressionZstd:
+		if cc.zdec == nil {
+			return nil, ErrInvalidCacheValue
+		}
+		return cc.zdec.DecodeAll(cv.Data, nil)
+	case CompressionGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(cv.Data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return nil, ErrInvalidCacheValue
+	}
+}
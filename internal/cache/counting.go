@@ -0,0 +1,44 @@
+package cache
+
+import "sync/atomic"
+
+// CountingCache counts hits, misses and sets of the wrapped cache, so
+// every configured cache has stats to report. Stats of the wrapped cache,
+// such as compression counters, are passed through.
+type CountingCache struct {
+	inner Cache
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	sets   atomic.Uint64
+}
+
+func NewCounting(inner Cache) *CountingCache {
+	return &CountingCache{inner: inner}
+}
+
+func (cc *CountingCache) Set(key string, value interface{}) error {
+	cc.sets.Add(1)
+	return cc.inner.Set(key, value)
+}
+
+func (cc *CountingCache) Get(key string) (interface{}, error) {
+	v, err := cc.inner.Get(key)
+	if err != nil {
+		cc.misses.Add(1)
+		return nil, err
+	}
+	cc.hits.Add(1)
+	return v, nil
+}
+
+func (cc *CountingCache) Stats() Stats {
+	var st Stats
+	if sr, ok := cc.inner.(StatsReporter); ok {
+		st = sr.Stats()
+	}
+	st.Hits = cc.hits.Load()
+	st.Misses = cc.misses.Load()
+	st.Sets = cc.sets.Load()
+	return st
+}
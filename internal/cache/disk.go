@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DiskCache keeps one gob encoded file per key under dir/namespace.
+// The file modification time is the entry creation time.
+type DiskCache struct {
+	ttl time.Duration
+	dir string
+}
+
+func NewDisk(dir, namespace string, ttl, clearTicker time.Duration) (*DiskCache, error) {
+	dir = filepath.Join(dir, namespace)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	cache := &DiskCache{
+		ttl: ttl,
+		dir: dir,
+	}
+
+	go func(cache *DiskCache) {
+		ticker := time.NewTicker(clearTicker)
+		for {
+			<-ticker.C
+			log.Println("running disk cache cleaning worker")
+			entries, err := os.ReadDir(cache.dir)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			for _, e := range entries {
+				info, err := e.Info()
+				if err != nil {
+					continue
+				}
+				if time.Since(info.ModTime()) > cache.ttl {
+					_ = os.Remove(filepath.Join(cache.dir, e.Name()))
+				}
+			}
+		}
+	}(cache)
+
+	return cache, nil
+}
+
+func (dc *DiskCache) Set(key string, value interface{}) error {
+	return dc.setEntry(key, cacheData{
+		createdAt: time.Now(),
+		value:     value,
+	})
+}
+
+func (dc *DiskCache) Get(key string) (interface{}, error) {
+	cd, err := dc.getEntry(key)
+	if err != nil {
+		return nil, err
+	}
+	return cd.value, nil
+}
+
+func (dc *DiskCache) setEntry(key string, cd cacheData) error {
+	data, err := encodeEntry(cd)
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file first so readers never see a partial entry
+	tmp, err := os.CreateTemp(dc.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), cd.createdAt, cd.createdAt); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dc.path(key))
+}
+
+func (dc *DiskCache) getEntry(key string) (cacheData, error) {
+	path := dc.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cacheData{}, ErrCacheMiss
+		}
+		return cacheData{}, err
+	}
+
+	cd, err := decodeEntry(data)
+	if err != nil {
+		_ = os.Remove(path)
+		return cacheData{}, err
+	}
+
+	if time.Since(cd.createdAt) > dc.ttl {
+		_ = os.Remove(path)
+		return cacheData{}, ErrTTLExpired
+	}
+
+	return cd, nil
+}
+
+func (dc *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// LayeredCache checks a local L1 first and falls back to a shared L2.
+// Writes go through to both levels; an L2 hit is copied back into L1
+// (read-repair) keeping the original entry age.
+type LayeredCache struct {
+	l1 Cache
+	l2 Cache
+}
+
+func NewLayered(l1, l2 Cache) *LayeredCache {
+	return &LayeredCache{
+		l1: l1,
+		l2: l2,
+	}
+}
+
+func (lc *LayeredCache) Set(key string, value interface{}) error {
+	cd := cacheData{
+		createdAt: time.Now(),
+		value:     value,
+	}
+	return errors.Join(setEntry(lc.l1, key, cd), setEntry(lc.l2, key, cd))
+}
+
+func (lc *LayeredCache) Get(key string) (interface{}, error) {
+	if v, err := lc.l1.Get(key); err == nil {
+		return v, nil
+	}
+
+	cd, err := getEntry(lc.l2, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setEntry(lc.l1, key, cd); err != nil {
+		// do not return here, the value itself is fine
+		log.Println(err)
+	}
+	return cd.value, nil
+}
+
+func getEntry(c Cache, key string) (cacheData, error) {
+	if es, ok := c.(entryStore); ok {
+		return es.getEntry(key)
+	}
+	v, err := c.Get(key)
+	if err != nil {
+		return cacheData{}, err
+	}
+	return cacheData{createdAt: time.Now(), value: v}, nil
+}
+
+func setEntry(c Cache, key string, cd cacheData) error {
+	if es, ok := c.(entryStore); ok {
+		return es.setEntry(key, cd)
+	}
+	return c.Set(key, cd.value)
+}
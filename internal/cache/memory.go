@@ -49,29 +49,40 @@ func NewInMemory(ttl, clearTicker time.Duration) *InMemoryCache {
 }
 
 func (imc *InMemoryCache) Set(key string, value interface{}) error {
-	cd := cacheData{
+	return imc.setEntry(key, cacheData{
 		createdAt: time.Now(),
 		value:     value,
+	})
+}
+
+func (imc *InMemoryCache) Get(key string) (interface{}, error) {
+	cd, err := imc.getEntry(key)
+	if err != nil {
+		return nil, err
 	}
+	return cd.value, nil
+}
+
+func (imc *InMemoryCache) setEntry(key string, cd cacheData) error {
 	imc.store.Store(key, cd)
 	return nil
 }
 
-func (imc *InMemoryCache) Get(key string) (interface{}, error) {
+func (imc *InMemoryCache) getEntry(key string) (cacheData, error) {
 	v, ok := imc.store.Load(key)
 	if !ok {
-		return nil, ErrCacheMiss
+		return cacheData{}, ErrCacheMiss
 	}
 
 	cd, ok := v.(cacheData)
 	if !ok {
-		return nil, ErrInvalidCacheValue
+		return cacheData{}, ErrInvalidCacheValue
 	}
 
 	if time.Since(cd.createdAt) > imc.ttl {
 		imc.store.Delete(key)
-		return nil, ErrTTLExpired
+		return cacheData{}, ErrTTLExpired
 	}
 
-	return cd.value, nil
+	return cd, nil
 }
@@ -0,0 +1,16 @@
+package cache
+
+// NoopCache stores nothing; every Get is a miss.
+type NoopCache struct{}
+
+func NewNoop() *NoopCache {
+	return &NoopCache{}
+}
+
+func (nc *NoopCache) Set(key string, value interface{}) error {
+	return nil
+}
+
+func (nc *NoopCache) Get(key string) (interface{}, error) {
+	return nil, ErrCacheMiss
+}
@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+type RedisOptions struct {
+	Addr     string
+	Password string
+	DB       int
+	// Timeout bounds dialing, every command round trip and the wait for a
+	// free connection.
+	Timeout time.Duration
+	// PoolSize caps the open connections; 0 means DefaultRedisPoolSize.
+	PoolSize int
+	// Prefix is prepended to every key.
+	Prefix string
+	TTL    time.Duration
+}
+
+// DefaultRedisPoolSize is the connection cap when RedisOptions.PoolSize is 0.
+const DefaultRedisPoolSize = 4
+
+// RedisCache is a shared cache backend speaking the RESP protocol over a
+// small pool of connections, so concurrent requests do not queue behind
+// one round trip. Expiration is left to redis (SET ... PX ttl).
+type RedisCache struct {
+	opts RedisOptions
+
+	slots chan struct{}   // one token per open or dialing connection
+	idle  chan *redisConn // connections ready for reuse
+}
+
+type redisConn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+var (
+	errRedisNil         = errors.New("redis: nil reply")
+	errRedisPoolTimeout = errors.New("redis: timed out waiting for a connection")
+)
+
+func NewRedis(opts RedisOptions) *RedisCache {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = DefaultRedisPoolSize
+	}
+	return &RedisCache{
+		opts:  opts,
+		slots: make(chan struct{}, opts.PoolSize),
+		idle:  make(chan *redisConn, opts.PoolSize),
+	}
+}
+
+func (rc *RedisCache) Set(key string, value interface{}) error {
+	return rc.setEntry(key, cacheData{
+		createdAt: time.Now(),
+		value:     value,
+	})
+}
+
+func (rc *RedisCache) Get(key string) (interface{}, error) {
+	cd, err := rc.getEntry(key)
+	if err != nil {
+		return nil, err
+	}
+	return cd.value, nil
+}
+
+func (rc *RedisCache) setEntry(key string, cd cacheData) error {
+	ttl := rc.opts.TTL - time.Since(cd.createdAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := encodeEntry(cd)
+	if err != nil {
+		return err
+	}
+
+	_, err = rc.do("SET", rc.opts.Prefix+key, string(data), "PX", strconv.FormatInt(ttl.Milliseconds()+1, 10))
+	return err
+}
+
+func (rc *RedisCache) getEntry(key string) (cacheData, error) {
+	reply, err := rc.do("GET", rc.opts.Prefix+key)
+	if err != nil {
+		if errors.Is(err, errRedisNil) {
+			return cacheData{}, ErrCacheMiss
+		}
+		return cacheData{}, err
+	}
+
+	data, ok := reply.([]byte)
+	if !ok {
+		return cacheData{}, ErrInvalidCacheValue
+	}
+
+	cd, err := decodeEntry(data)
+	if err != nil {
+		return cacheData{}, err
+	}
+	if time.Since(cd.createdAt) > rc.opts.TTL {
+		return cacheData{}, ErrTTLExpired
+	}
+	return cd, nil
+}
+
+// do sends one command on a pooled connection and reads its reply.
+// Connections in an unknown state are closed instead of returned.
+func (rc *RedisCache) do(args ...string) (interface{}, error) {
+	c, err := rc.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.roundTrip(rc.opts.Timeout, args...)
+	if err != nil && !errors.Is(err, errRedisNil) {
+		var re redisError
+		if !errors.As(err, &re) {
+			rc.release(nil)
+			_ = c.conn.Close()
+			return reply, err
+		}
+	}
+	rc.release(c)
+	return reply, err
+}
+
+// acquire takes an idle connection or dials a new one while the pool has
+// room, waiting at most Timeout for either.
+func (rc *RedisCache) acquire() (*redisConn, error) {
+	select {
+	case c := <-rc.idle:
+		return c, nil
+	default:
+	}
+
+	var timeout <-chan time.Time
+	if rc.opts.Timeout > 0 {
+		t := time.NewTimer(rc.opts.Timeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case c := <-rc.idle:
+		return c, nil
+	case rc.slots <- struct{}{}:
+	case <-timeout:
+		return nil, errRedisPoolTimeout
+	}
+
+	c, err := rc.connect()
+	if err != nil {
+		<-rc.slots
+		return nil, err
+	}
+	return c, nil
+}
+
+// release hands c back to the pool; a nil c frees the slot of a closed
+// connection.
+func (rc *RedisCache) release(c *redisConn) {
+	if c == nil {
+		<-rc.slots
+		return
+	}
+	// idle has room for every slot, so this never blocks
+	rc.idle <- c
+}
+
+func (rc *RedisCache) connect() (*redisConn, error) {
+	conn, err := net.DialTimeout("tcp", rc.opts.Addr, rc.opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, rd: bufio.NewReader(conn)}
+
+	if rc.opts.Password != "" {
+		if _, err := c.roundTrip(rc.opts.Timeout, "AUTH", rc.opts.Password); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	if rc.opts.DB != 0 {
+		if _, err := c.roundTrip(rc.opts.Timeout, "SELECT", strconv.Itoa(rc.opts.DB)); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *redisConn) roundTrip(timeout time.Duration, args ...string) (interface{}, error) {
+	if timeout > 0 {
+		_ = c.conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, a := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(a)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, a...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.rd)
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+func readReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		items := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := readReply(rd)
+			if err != nil && !errors.Is(err, errRedisNil) {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", kind)
+	}
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// ShardedCache spreads keys over independently locked shards to reduce
+// contention under parallel load. Each shard tracks expirations in a
+// min-heap, so the cleaning worker only touches entries that are due
+// instead of scanning the whole store.
+type ShardedCache struct {
+	ttl    time.Duration
+	shards []*shard
+	mask   uint32
+}
+
+type shard struct {
+	mu       sync.RWMutex
+	items    map[string]*entry
+	expiries expiryHeap
+}
+
+// entry is a stored value together with its position in the expiry heap,
+// so an overwrite moves the existing heap entry instead of adding one.
+type entry struct {
+	key   string
+	data  cacheData
+	index int
+}
+
+// expiryHeap orders entries by creation time; with a single TTL per cache
+// that is also the expiration order.
+type expiryHeap []*entry
+
+func (h expiryHeap) Len() int { return len(h) }
+func (h expiryHeap) Less(i, j int) bool {
+	return h[i].data.createdAt.Before(h[j].data.createdAt)
+}
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+func (h *expiryHeap) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *expiryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return x
+}
+
+// NewSharded creates a cache with shardCount shards, rounded up to a power of two.
+func NewSharded(ttl, clearTicker time.Duration, shardCount int) *ShardedCache {
+	n := 1
+	for n < shardCount {
+		n <<= 1
+	}
+
+	cache := &ShardedCache{
+		ttl:    ttl,
+		shards: make([]*shard, n),
+		mask:   uint32(n - 1),
+	}
+	for i := range cache.shards {
+		cache.shards[i] = &shard{items: make(map[string]*entry)}
+	}
+
+	go func(cache *ShardedCache) {
+		ticker := time.NewTicker(clearTicker)
+		for {
+			<-ticker.C
+			cache.evictExpired(time.Now())
+		}
+	}(cache)
+
+	return cache
+}
+
+func (sc *ShardedCache) Set(key string, value interface{}) error {
+	return sc.setEntry(key, cacheData{
+		createdAt: time.Now(),
+		value:     value,
+	})
+}
+
+func (sc *ShardedCache) Get(key string) (interface{}, error) {
+	cd, err := sc.getEntry(key)
+	if err != nil {
+		return nil, err
+	}
+	return cd.value, nil
+}
+
+func (sc *ShardedCache) setEntry(key string, cd cacheData) error {
+	s := sc.shard(key)
+	s.mu.Lock()
+	if e, ok := s.items[key]; ok {
+		e.data = cd
+		heap.Fix(&s.expiries, e.index)
+	} else {
+		e := &entry{key: key, data: cd}
+		s.items[key] = e
+		heap.Push(&s.expiries, e)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (sc *ShardedCache) getEntry(key string) (cacheData, error) {
+	s := sc.shard(key)
+	s.mu.RLock()
+	e, ok := s.items[key]
+	if !ok {
+		s.mu.RUnlock()
+		return cacheData{}, ErrCacheMiss
+	}
+	cd := e.data
+	s.mu.RUnlock()
+
+	if time.Since(cd.createdAt) > sc.ttl {
+		// the cleaning worker removes it together with its heap entry
+		return cacheData{}, ErrTTLExpired
+	}
+
+	return cd, nil
+}
+
+func (sc *ShardedCache) evictExpired(now time.Time) {
+	deadline := now.Add(-sc.ttl)
+	for _, s := range sc.shards {
+		s.mu.Lock()
+		for len(s.expiries) > 0 && s.expiries[0].data.createdAt.Before(deadline) {
+			e := heap.Pop(&s.expiries).(*entry)
+			delete(s.items, e.key)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (sc *ShardedCache) shard(key string) *shard {
+	// FNV-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return sc.shards[h&sc.mask]
+}
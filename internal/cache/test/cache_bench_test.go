@@ -0,0 +1,60 @@
+package test
+
+import (
+	"interview-go/internal/cache"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// run with: go test -bench=. -benchmem ./internal/cache/test/
+
+const benchKeys = 1024
+
+func benchmarkParallel(b *testing.B, c cache.Cache, writeEvery int) {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = "filtered:" + strconv.Itoa(i)
+		_ = c.Set(keys[i], i)
+	}
+
+	var seed atomic.Uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(seed.Add(1) * 7919)
+		for pb.Next() {
+			i++
+			key := keys[i%benchKeys]
+			if writeEvery > 0 && i%writeEvery == 0 {
+				_ = c.Set(key, i)
+				continue
+			}
+			_, _ = c.Get(key)
+		}
+	})
+}
+
+func BenchmarkInMemory_ReadHeavy(b *testing.B) {
+	benchmarkParallel(b, cache.NewInMemory(time.Minute, time.Minute), 10)
+}
+
+func BenchmarkSharded_ReadHeavy(b *testing.B) {
+	benchmarkParallel(b, cache.NewSharded(time.Minute, time.Minute, 16), 10)
+}
+
+func BenchmarkInMemory_WriteHeavy(b *testing.B) {
+	benchmarkParallel(b, cache.NewInMemory(time.Minute, time.Minute), 2)
+}
+
+func BenchmarkSharded_WriteHeavy(b *testing.B) {
+	benchmarkParallel(b, cache.NewSharded(time.Minute, time.Minute, 16), 2)
+}
+
+func BenchmarkInMemory_ReadOnly(b *testing.B) {
+	benchmarkParallel(b, cache.NewInMemory(time.Minute, time.Minute), 0)
+}
+
+func BenchmarkSharded_ReadOnly(b *testing.B) {
+	benchmarkParallel(b, cache.NewSharded(time.Minute, time.Minute, 16), 0)
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"interview-go/internal/cache"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedCache_RoundTrip(t *testing.T) {
+	for _, algorithm := range []string{cache.CompressionGzip, cache.CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			cc, err := cache.NewCompressed(cache.NewInMemory(time.Minute, time.Minute), algorithm, 64)
+			require.NoError(t, err)
+			c := cache.NewCounting(cc)
+
+			large := strings.Repeat("hoppy citrus ", 100)
+			require.NoError(t, c.Set("large", large))
+			require.NoError(t, c.Set("small", "ipa"))
+
+			v, err := c.Get("large")
+			require.NoError(t, err)
+			require.Equal(t, large, v)
+
+			v, err = c.Get("small")
+			require.NoError(t, err)
+			require.Equal(t, "ipa", v)
+
+			st := c.Stats()
+			require.Equal(t, uint64(1), st.Compressed)
+			require.Equal(t, uint64(2), st.Hits)
+			require.Greater(t, st.CompressionRatio, 1.0)
+		})
+	}
+}
+
+func TestCompressedCache_UnknownAlgorithm(t *testing.T) {
+	_, err := cache.NewCompressed(cache.NewNoop(), "lz4", 64)
+	require.Error(t, err)
+}
+
+// sized cannot be gob encoded, so storing it proves small sized values
+// are not encoded.
+type sized struct {
+	ch chan int
+}
+
+func (sized) CacheSize() int { return 8 }
+
+func TestCompressedCache_SmallSizedValuesSkipEncoding(t *testing.T) {
+	c, err := cache.NewCompressed(cache.NewInMemory(time.Minute, time.Minute), cache.CompressionGzip, 64)
+	require.NoError(t, err)
+
+	v := sized{ch: make(chan int)}
+	require.NoError(t, c.Set("k", v))
+
+	got, err := c.Get("k")
+	require.NoError(t, err)
+	require.Equal(t, v, got)
+	require.Zero(t, c.Stats().Compressed)
+}
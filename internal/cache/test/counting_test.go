@@ -0,0 +1,25 @@
+package test
+
+import (
+	"interview-go/internal/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountingCache_Stats(t *testing.T) {
+	c := cache.NewCounting(cache.NewInMemory(time.Minute, time.Minute))
+
+	require.NoError(t, c.Set("a", 1))
+	_, err := c.Get("a")
+	require.NoError(t, err)
+	_, err = c.Get("b")
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+
+	st := c.Stats()
+	require.Equal(t, uint64(1), st.Hits)
+	require.Equal(t, uint64(1), st.Misses)
+	require.Equal(t, uint64(1), st.Sets)
+	require.Zero(t, st.Compressed)
+}
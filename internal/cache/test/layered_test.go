@@ -0,0 +1,41 @@
+package test
+
+import (
+	"interview-go/internal/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayeredCache_WriteThroughAndReadRepair(t *testing.T) {
+	l2, err := cache.NewDisk(t.TempDir(), "beers", time.Minute, time.Minute)
+	require.NoError(t, err)
+
+	writer := cache.NewLayered(cache.NewInMemory(time.Minute, time.Minute), l2)
+	require.NoError(t, writer.Set("key", "value"))
+
+	// the shared level has the value written through
+	v, err := l2.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+
+	// a second instance with an empty L1 repairs it from L2
+	l1 := cache.NewInMemory(time.Minute, time.Minute)
+	reader := cache.NewLayered(l1, l2)
+
+	v, err = reader.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+
+	v, err = l1.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, "value", v)
+}
+
+func TestLayeredCache_Miss(t *testing.T) {
+	c := cache.NewLayered(cache.NewInMemory(time.Minute, time.Minute), cache.NewNoop())
+
+	_, err := c.Get("missing")
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+}
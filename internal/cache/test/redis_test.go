@@ -0,0 +1,277 @@
+package test
+
+import (
+	"bufio"
+	"fmt"
+	"interview-go/internal/cache"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis speaks just enough RESP to serve GET and SET. Keys starting
+// with "fail" get an error reply, keys starting with "drop" close the
+// connection without a reply and keys starting with "slow" are answered
+// after slowReply.
+type fakeRedis struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	data     map[string]string
+	commands [][]string
+	conns    int
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	fr := &fakeRedis{ln: ln, data: make(map[string]string)}
+	go fr.serve()
+	return fr
+}
+
+func (fr *fakeRedis) serve() {
+	for {
+		conn, err := fr.ln.Accept()
+		if err != nil {
+			return
+		}
+		fr.mu.Lock()
+		fr.conns++
+		fr.mu.Unlock()
+		go fr.handle(conn)
+	}
+}
+
+func (fr *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+		fr.mu.Lock()
+		fr.commands = append(fr.commands, args)
+		fr.mu.Unlock()
+
+		key := ""
+		if len(args) > 1 {
+			key = args[1]
+		}
+		var reply string
+		switch {
+		case strings.HasPrefix(key, "drop"):
+			return
+		case strings.HasPrefix(key, "fail"):
+			reply = "-ERR boom\r\n"
+		case strings.HasPrefix(key, "slow"):
+			time.Sleep(slowReply)
+			reply = "$-1\r\n"
+		case args[0] == "AUTH" || args[0] == "SELECT":
+			reply = "+OK\r\n"
+		case args[0] == "SET":
+			fr.mu.Lock()
+			fr.data[key] = args[2]
+			fr.mu.Unlock()
+			reply = "+OK\r\n"
+		case args[0] == "GET":
+			fr.mu.Lock()
+			v, ok := fr.data[key]
+			fr.mu.Unlock()
+			if !ok {
+				reply = "$-1\r\n"
+			} else {
+				reply = "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+const slowReply = 100 * time.Millisecond
+
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(rd, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(rd, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		if string(buf[size:]) != "\r\n" {
+			return nil, fmt.Errorf("argument %d not terminated by CRLF", i)
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (fr *fakeRedis) lastCommands(n int) [][]string {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return fr.commands[len(fr.commands)-n:]
+}
+
+func (fr *fakeRedis) connCount() int {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	return fr.conns
+}
+
+func newTestRedis(fr *fakeRedis, opts cache.RedisOptions) *cache.RedisCache {
+	opts.Addr = fr.ln.Addr().String()
+	opts.Timeout = time.Second
+	if opts.TTL == 0 {
+		opts.TTL = time.Minute
+	}
+	return cache.NewRedis(opts)
+}
+
+func TestRedisCache_SetGet(t *testing.T) {
+	fr := newFakeRedis(t)
+	c := newTestRedis(fr, cache.RedisOptions{Prefix: "beers:"})
+
+	// binary safe framing: the value contains CRLF and RESP markers
+	value := "line\r\n$3\r\n*1\r\n"
+	require.NoError(t, c.Set("k", value))
+
+	v, err := c.Get("k")
+	require.NoError(t, err)
+	require.Equal(t, value, v)
+
+	cmds := fr.lastCommands(2)
+	require.Equal(t, []string{"SET", "beers:k"}, cmds[0][:2])
+	require.Equal(t, "PX", cmds[0][3])
+	ttl, err := strconv.Atoi(cmds[0][4])
+	require.NoError(t, err)
+	require.InDelta(t, time.Minute.Milliseconds(), ttl, 1000)
+	require.Equal(t, []string{"GET", "beers:k"}, cmds[1])
+}
+
+func TestRedisCache_NilBulkIsMiss(t *testing.T) {
+	fr := newFakeRedis(t)
+	c := newTestRedis(fr, cache.RedisOptions{})
+
+	_, err := c.Get("missing")
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+	require.Equal(t, 1, fr.connCount())
+}
+
+func TestRedisCache_ErrorReplyKeepsConnection(t *testing.T) {
+	fr := newFakeRedis(t)
+	c := newTestRedis(fr, cache.RedisOptions{})
+
+	_, err := c.Get("fail")
+	require.ErrorContains(t, err, "redis: ERR boom")
+
+	_, err = c.Get("missing")
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+	require.Equal(t, 1, fr.connCount())
+}
+
+func TestRedisCache_ReconnectsAfterBrokenConnection(t *testing.T) {
+	fr := newFakeRedis(t)
+	c := newTestRedis(fr, cache.RedisOptions{Password: "secret", DB: 2})
+
+	_, err := c.Get("drop")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, cache.ErrCacheMiss)
+
+	require.NoError(t, c.Set("k", "v"))
+	v, err := c.Get("k")
+	require.NoError(t, err)
+	require.Equal(t, "v", v)
+	require.Equal(t, 2, fr.connCount())
+
+	// the new connection authenticates and selects the database again
+	require.Equal(t, [][]string{{"AUTH", "secret"}, {"SELECT", "2"}}, fr.lastCommands(4)[:2])
+}
+
+func TestRedisCache_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	c := cache.NewRedis(cache.RedisOptions{Addr: addr, Timeout: time.Second, TTL: time.Minute})
+	_, err = c.Get("k")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, cache.ErrCacheMiss)
+}
+
+func TestRedisCache_ConcurrentCommandsUsePool(t *testing.T) {
+	fr := newFakeRedis(t)
+	c := newTestRedis(fr, cache.RedisOptions{PoolSize: 2})
+
+	getSlow := func(n int) time.Duration {
+		start := time.Now()
+		var wg sync.WaitGroup
+		for range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.Get("slow")
+				require.ErrorIs(t, err, cache.ErrCacheMiss)
+			}()
+		}
+		wg.Wait()
+		return time.Since(start)
+	}
+
+	// two slow commands run side by side instead of queueing
+	require.Less(t, getSlow(2), 2*slowReply)
+	require.Equal(t, 2, fr.connCount())
+
+	// the pool caps the connections; a third command waits for one
+	require.GreaterOrEqual(t, getSlow(3), 2*slowReply)
+	require.Equal(t, 2, fr.connCount())
+}
+
+func TestRedisCache_PoolTimeout(t *testing.T) {
+	fr := newFakeRedis(t)
+	timeout := slowReply / 2
+	c := cache.NewRedis(cache.RedisOptions{Addr: fr.ln.Addr().String(), Timeout: timeout, TTL: time.Minute, PoolSize: 1})
+
+	// every round trip outlasts its deadline: the first command holds the
+	// only connection until timeout, the second takes it over right after
+	getSlow := func(delay time.Duration) <-chan error {
+		errc := make(chan error, 1)
+		go func() {
+			time.Sleep(delay)
+			_, err := c.Get("slow")
+			errc <- err
+		}()
+		return errc
+	}
+	first, second := getSlow(0), getSlow(timeout/10)
+
+	// the third waits while the second holds the connection and gives up
+	third := getSlow(timeout / 2)
+	require.ErrorContains(t, <-third, "timed out waiting for a connection")
+	require.ErrorContains(t, <-first, "i/o timeout")
+	require.ErrorContains(t, <-second, "i/o timeout")
+
+	// the slots of the dropped connections are free again
+	_, err := c.Get("k")
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"interview-go/internal/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedCache_SetGet(t *testing.T) {
+	c := cache.NewSharded(time.Minute, time.Minute, 8)
+
+	require.NoError(t, c.Set("a", 1))
+	require.NoError(t, c.Set("b", 2))
+	require.NoError(t, c.Set("a", 3))
+
+	v, err := c.Get("a")
+	require.NoError(t, err)
+	require.Equal(t, 3, v)
+
+	v, err = c.Get("b")
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+
+	_, err = c.Get("c")
+	require.ErrorIs(t, err, cache.ErrCacheMiss)
+}
+
+func TestShardedCache_Expiration(t *testing.T) {
+	c := cache.NewSharded(time.Millisecond, 5*time.Millisecond, 4)
+	require.NoError(t, c.Set("a", 1))
+
+	time.Sleep(2 * time.Millisecond)
+	_, err := c.Get("a")
+	require.ErrorIs(t, err, cache.ErrTTLExpired)
+
+	// the cleaning worker drops the entry altogether
+	require.Eventually(t, func() bool {
+		_, err := c.Get("a")
+		return err == cache.ErrCacheMiss
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestShardedCache_OverwriteRefreshesExpiry(t *testing.T) {
+	c := cache.NewSharded(200*time.Millisecond, 5*time.Millisecond, 1)
+	require.NoError(t, c.Set("a", 1))
+	require.NoError(t, c.Set("b", 2))
+
+	time.Sleep(100 * time.Millisecond)
+	for i := range 1000 {
+		require.NoError(t, c.Set("a", i))
+	}
+
+	require.Eventually(t, func() bool {
+		_, err := c.Get("b")
+		return err == cache.ErrCacheMiss
+	}, time.Second, 5*time.Millisecond)
+
+	v, err := c.Get("a")
+	require.NoError(t, err)
+	require.Equal(t, 999, v)
+}
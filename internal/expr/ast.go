@@ -0,0 +1,129 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Node is a parsed boolean expression.
+type Node interface {
+	// Pos is the byte offset of the node in the source.
+	Pos() int
+	// String renders the node in a canonical form, equal for equivalent
+	// spellings, so it can be used in cache keys.
+	String() string
+}
+
+// Logical joins two expressions with "and" or "or".
+type Logical struct {
+	Op          string
+	Left, Right Node
+	OpPos       int
+}
+
+// Not negates an expression.
+type Not struct {
+	X      Node
+	NotPos int
+}
+
+// Comparison compares a field with a literal, e.g. abv >= 5.
+type Comparison struct {
+	Field    string
+	FieldPos int
+	Op       string // one of = != < <= > >= ~ !~
+	OpPos    int
+	Value    Literal
+}
+
+// LiteralKind is the type of a literal value.
+type LiteralKind int
+
+const (
+	Number LiteralKind = iota
+	String
+	Bool
+)
+
+func (k LiteralKind) String() string {
+	switch k {
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	default:
+		return "boolean"
+	}
+}
+
+// Literal is a constant value.
+type Literal struct {
+	Kind   LiteralKind
+	Num    float64
+	Str    string
+	Bool   bool
+	ValPos int
+}
+
+func (n *Logical) Pos() int    { return n.Left.Pos() }
+func (n *Not) Pos() int        { return n.NotPos }
+func (n *Comparison) Pos() int { return n.FieldPos }
+func (l Literal) Pos() int     { return l.ValPos }
+
+func (n *Logical) String() string {
+	return "(" + n.Left.String() + " " + n.Op + " " + n.Right.String() + ")"
+}
+
+func (n *Not) String() string {
+	return "not " + n.X.String()
+}
+
+func (n *Comparison) String() string {
+	return n.Field + " " + n.Op + " " + n.Value.String()
+}
+
+func (l Literal) String() string {
+	switch l.Kind {
+	case Number:
+		return strconv.FormatFloat(l.Num, 'g', -1, 64)
+	case String:
+		return strconv.Quote(l.Str)
+	default:
+		return strconv.FormatBool(l.Bool)
+	}
+}
+
+// Error is a syntax or type error at a position of the source.
+type Error struct {
+	// Pos is the byte offset of the error in the source.
+	Pos int
+	Msg string
+
+	// Column is the 1-based character position shown to users; it is set
+	// by Parse and Errorf.
+	Column int
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Column, e.Msg)
+}
+
+// Errorf builds an Error at the byte offset pos of src, for checks done
+// after parsing such as type checking.
+func Errorf(src string, pos int, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...), Column: column(src, pos)}
+}
+
+func column(src string, pos int) int {
+	pos = min(max(pos, 0), len(src))
+	return utf8.RuneCountInString(src[:pos]) + 1
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+func quoteRune(r rune) string {
+	return strconv.QuoteRune(r)
+}
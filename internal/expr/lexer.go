@@ -0,0 +1,169 @@
+package expr
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTrue
+	tokFalse
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of expression"
+	case tokIdent:
+		return "field name"
+	case tokNumber:
+		return "number"
+	case tokString:
+		return "string"
+	case tokOp:
+		return "operator"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokAnd:
+		return `"and"`
+	case tokOr:
+		return `"or"`
+	case tokNot:
+		return `"not"`
+	default:
+		return "boolean"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	text string // identifier, operator or decoded string
+	pos  int    // byte offset in the source
+}
+
+var keywords = map[string]tokenKind{
+	"and":   tokAnd,
+	"or":    tokOr,
+	"not":   tokNot,
+	"true":  tokTrue,
+	"false": tokFalse,
+}
+
+// operators are matched longest first.
+var operators = []string{"==", "!=", "<=", ">=", "!~", "=", "<", ">", "~"}
+
+type lexer struct {
+	src string
+	off int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.off < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.off:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.off += size
+	}
+	if l.off >= len(l.src) {
+		return token{kind: tokEOF, pos: l.off}, nil
+	}
+
+	start := l.off
+	r, _ := utf8.DecodeRuneInString(l.src[l.off:])
+	switch {
+	case r == '(':
+		l.off++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case r == ')':
+		l.off++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case r == '"' || r == '\'':
+		return l.string(r)
+	case r == '-' || r == '.' || unicode.IsDigit(r):
+		return l.number()
+	case r == '_' || unicode.IsLetter(r):
+		return l.ident(), nil
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.off:], op) {
+			l.off += len(op)
+			if op == "==" {
+				op = "="
+			}
+			return token{kind: tokOp, text: op, pos: start}, nil
+		}
+	}
+	return token{}, &Error{Pos: start, Msg: "unexpected character " + quoteRune(r)}
+}
+
+func (l *lexer) ident() token {
+	start := l.off
+	for l.off < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.off:])
+		if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		l.off += size
+	}
+	text := l.src[start:l.off]
+	if kind, ok := keywords[strings.ToLower(text)]; ok {
+		return token{kind: kind, text: strings.ToLower(text), pos: start}
+	}
+	return token{kind: tokIdent, text: text, pos: start}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.off
+	if l.src[l.off] == '-' {
+		l.off++
+	}
+	digits := 0
+	for l.off < len(l.src) && (l.src[l.off] == '.' || unicode.IsDigit(rune(l.src[l.off]))) {
+		if l.src[l.off] != '.' {
+			digits++
+		}
+		l.off++
+	}
+	if digits == 0 {
+		return token{}, &Error{Pos: start, Msg: "malformed number " + quote(l.src[start:l.off])}
+	}
+	return token{kind: tokNumber, text: l.src[start:l.off], pos: start}, nil
+}
+
+// string reads a quoted string; a backslash escapes the next character.
+func (l *lexer) string(q rune) (token, error) {
+	start := l.off
+	l.off++
+
+	var sb strings.Builder
+	for l.off < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.off:])
+		l.off += size
+		switch {
+		case r == q:
+			return token{kind: tokString, text: sb.String(), pos: start}, nil
+		case r == '\\' && l.off < len(l.src):
+			r, size = utf8.DecodeRuneInString(l.src[l.off:])
+			l.off += size
+		}
+		sb.WriteRune(r)
+	}
+	return token{}, &Error{Pos: start, Msg: "unterminated string"}
+}
@@ -0,0 +1,217 @@
+package expr
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Limits bound the size of an expression so a request cannot make the
+// parser or the compiled filter arbitrarily expensive.
+type Limits struct {
+	MaxLength      int // bytes of source
+	MaxDepth       int // nesting of parentheses and operators
+	MaxComparisons int
+}
+
+// DefaultLimits are used when a limit is zero.
+var DefaultLimits = Limits{MaxLength: 1024, MaxDepth: 16, MaxComparisons: 32}
+
+// Parse parses src with the grammar
+//
+//	expr       = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" expr ")" | comparison
+//	comparison = field op literal
+//	op         = "=" | "==" | "!=" | "<" | "<=" | ">" | ">=" | "~" | "!~"
+//	literal    = number | string | "true" | "false"
+//
+// Keywords are case-insensitive and strings use single or double quotes.
+func Parse(src string, limits Limits) (Node, error) {
+	limits = limits.withDefaults()
+
+	p := &parser{lex: lexer{src: src}, limits: limits}
+	n, err := p.parse()
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			e.Column = column(src, e.Pos)
+		}
+		return nil, err
+	}
+	return n, nil
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxLength <= 0 {
+		l.MaxLength = DefaultLimits.MaxLength
+	}
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = DefaultLimits.MaxDepth
+	}
+	if l.MaxComparisons <= 0 {
+		l.MaxComparisons = DefaultLimits.MaxComparisons
+	}
+	return l
+}
+
+type parser struct {
+	lex    lexer
+	tok    token
+	limits Limits
+
+	depth       int
+	comparisons int
+}
+
+func (p *parser) parse() (Node, error) {
+	if len(p.lex.src) > p.limits.MaxLength {
+		return nil, &Error{Pos: p.limits.MaxLength, Msg: "expression is longer than " + strconv.Itoa(p.limits.MaxLength) + " bytes"}
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, &Error{Pos: 0, Msg: "empty expression"}
+	}
+
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.unexpected("\"and\", \"or\" or end of expression")
+	}
+	return n, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected(want string) error {
+	got := p.tok.kind.String()
+	if p.tok.text != "" && p.tok.kind != tokString {
+		got += " " + quote(p.tok.text)
+	}
+	return &Error{Pos: p.tok.pos, Msg: "expected " + want + ", got " + got}
+}
+
+// enter guards the recursion depth.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > p.limits.MaxDepth {
+		return &Error{Pos: p.tok.pos, Msg: "expression is nested deeper than " + strconv.Itoa(p.limits.MaxDepth) + " levels"}
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) or() (Node, error) {
+	return p.logical("or", tokOr, p.and)
+}
+
+func (p *parser) and() (Node, error) {
+	return p.logical("and", tokAnd, p.unary)
+}
+
+func (p *parser) logical(op string, kind tokenKind, operand func() (Node, error)) (Node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == kind {
+		opPos := p.tok.pos
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: op, Left: left, Right: right, OpPos: opPos}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (Node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	switch p.tok.kind {
+	case tokNot:
+		pos := p.tok.pos
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x, NotPos: pos}, nil
+	case tokLParen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.unexpected(`")"`)
+		}
+		return n, p.advance()
+	case tokIdent:
+		return p.comparison()
+	default:
+		return nil, p.unexpected(`field name, "not" or "("`)
+	}
+}
+
+func (p *parser) comparison() (Node, error) {
+	p.comparisons++
+	if p.comparisons > p.limits.MaxComparisons {
+		return nil, &Error{Pos: p.tok.pos, Msg: "expression has more than " + strconv.Itoa(p.limits.MaxComparisons) + " comparisons"}
+	}
+
+	n := &Comparison{Field: p.tok.text, FieldPos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokOp {
+		return nil, p.unexpected("comparison operator")
+	}
+	n.Op, n.OpPos = p.tok.text, p.tok.pos
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	lit := Literal{ValPos: p.tok.pos}
+	switch p.tok.kind {
+	case tokNumber:
+		v, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return nil, &Error{Pos: p.tok.pos, Msg: "malformed number " + quote(p.tok.text)}
+		}
+		lit.Kind, lit.Num = Number, v
+	case tokString:
+		lit.Kind, lit.Str = String, p.tok.text
+	case tokTrue, tokFalse:
+		lit.Kind, lit.Bool = Bool, p.tok.kind == tokTrue
+	default:
+		return nil, p.unexpected("number, string or boolean")
+	}
+	n.Value = lit
+
+	return n, p.advance()
+}
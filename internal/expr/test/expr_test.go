@@ -0,0 +1,68 @@
+package test
+
+import (
+	"interview-go/internal/expr"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_PrecedenceAndCanonicalForm(t *testing.T) {
+	n, err := expr.Parse(`abv >= 5 AND abv < 7 and (food ~ "chicken" or food ~ 'lamb') and not style == "stout"`, expr.Limits{})
+	require.NoError(t, err)
+	require.Equal(t, `(((abv >= 5 and abv < 7) and (food ~ "chicken" or food ~ "lamb")) and not style = "stout")`, n.String())
+
+	// and binds tighter than or
+	n, err = expr.Parse(`a = 1 or b = 2 and c = true`, expr.Limits{})
+	require.NoError(t, err)
+	require.Equal(t, `(a = 1 or (b = 2 and c = true))`, n.String())
+}
+
+func TestParse_Literals(t *testing.T) {
+	n, err := expr.Parse(`name = "say \"hi\"" and abv > -1.5 and ingredients.hops.name != 'O\'Brien'`, expr.Limits{})
+	require.NoError(t, err)
+	require.Equal(t, `((name = "say \"hi\"" and abv > -1.5) and ingredients.hops.name != "O'Brien")`, n.String())
+}
+
+func TestParse_ErrorPositions(t *testing.T) {
+	cases := []struct {
+		src    string
+		column int
+		msg    string
+	}{
+		{``, 1, "empty expression"},
+		{`abv >= `, 8, "expected number, string or boolean, got end of expression"},
+		{`abv 5`, 5, "expected comparison operator, got number"},
+		{`(abv > 5`, 9, `expected ")"`},
+		{`abv > 5 abv < 7`, 9, `expected "and", "or" or end of expression`},
+		{`name = "open`, 8, "unterminated string"},
+		{`abv > 5 & abv < 7`, 9, "unexpected character '&'"},
+		{`abv > 1.2.3`, 7, "malformed number"},
+		{`ñame = 1 and and`, 14, `expected field name, "not" or "(", got "and"`},
+	}
+
+	for _, tc := range cases {
+		_, err := expr.Parse(tc.src, expr.Limits{})
+		var e *expr.Error
+		require.ErrorAs(t, err, &e, tc.src)
+		require.Equal(t, tc.column, e.Column, tc.src)
+		require.Contains(t, e.Error(), tc.msg, tc.src)
+	}
+}
+
+func TestParse_Limits(t *testing.T) {
+	limits := expr.Limits{MaxLength: 64, MaxDepth: 3, MaxComparisons: 2}
+
+	_, err := expr.Parse(strings.Repeat(" ", 65), limits)
+	require.ErrorContains(t, err, "longer than 64 bytes")
+
+	_, err = expr.Parse(`not not not not a = 1`, limits)
+	require.ErrorContains(t, err, "nested deeper than 3 levels")
+
+	_, err = expr.Parse(`a = 1 and b = 2 and c = 3`, limits)
+	require.ErrorContains(t, err, "more than 2 comparisons")
+
+	_, err = expr.Parse(`((a = 1)) and b = 2`, limits)
+	require.NoError(t, err)
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Field is a piece of document text; terms found in it count Weight times.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Hit is a matching document with its relevance score.
+type Hit struct {
+	Doc   int
+	Score float64
+}
+
+type posting struct {
+	doc int
+	tf  float64
+}
+
+// Index is an immutable inverted index ranking documents with BM25.
+// Build it with NewIndex.
+type Index struct {
+	postings map[string][]posting
+	docLen   map[int]float64
+	avgLen   float64
+}
+
+// NewIndex indexes docs, keyed by document ID.
+func NewIndex(docs map[int][]Field) *Index {
+	idx := &Index{
+		postings: make(map[string][]posting),
+		docLen:   make(map[int]float64, len(docs)),
+	}
+
+	total := 0.0
+	for doc, fields := range docs {
+		tf := make(map[string]float64)
+		length := 0.0
+		for _, f := range fields {
+			for _, term := range Tokenize(f.Text) {
+				tf[term] += f.Weight
+				length += f.Weight
+			}
+		}
+		for term, n := range tf {
+			idx.postings[term] = append(idx.postings[term], posting{doc: doc, tf: n})
+		}
+		idx.docLen[doc] = length
+		total += length
+	}
+	if len(docs) > 0 {
+		idx.avgLen = total / float64(len(docs))
+	}
+
+	return idx
+}
+
+// Search ranks the documents matching any term of query, best first.
+// Ties are ordered by document ID.
+func (idx *Index) Search(query string) []Hit {
+	n := float64(len(idx.docLen))
+	scores := make(map[int]float64)
+
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			norm := p.tf + k1*(1-b+b*idx.docLen[p.doc]/idx.avgLen)
+			scores[p.doc] += idf * p.tf * (k1 + 1) / norm
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		hits = append(hits, Hit{Doc: doc, Score: score})
+	}
+	slices.SortFunc(hits, func(x, y Hit) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Doc, y.Doc)
+	})
+	return hits
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"interview-go/internal/search"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+	cases := map[string]string{
+		"hops":         "hop",
+		"hopped":       "hop",
+		"hopping":      "hop",
+		"caresses":     "caress",
+		"ponies":       "poni",
+		"citrusy":      "citrusi",
+		"brewed":       "brew",
+		"brewing":      "brew",
+		"relational":   "relat",
+		"hopefulness":  "hope",
+		"bitterness":   "bitter",
+		"fermentation": "ferment",
+		"ipa":          "ipa",
+	}
+	for word, want := range cases {
+		require.Equal(t, want, search.Stem(word), word)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	require.Equal(t, []string{"hazi", "ipa", "citru", "hop"}, search.Tokenize("A Hazy IPA, with the citrus hops!"))
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := search.NewIndex(map[int][]search.Field{
+		1: {{Text: "Citrus Hazy IPA", Weight: 3}, {Text: "Juicy and hazy with citrus hops", Weight: 1}},
+		2: {{Text: "Dark Stout", Weight: 3}, {Text: "Roasty with a hint of citrus peel", Weight: 1}},
+		3: {{Text: "Pilsner", Weight: 3}, {Text: "Crisp and clean", Weight: 1}},
+		4: {{Text: "Hazy Pale", Weight: 3}, {Text: "Soft and hazy", Weight: 1}},
+	})
+
+	hits := idx.Search("citrus hazy")
+	require.Len(t, hits, 3)
+	require.Equal(t, 1, hits[0].Doc)
+	for i := 1; i < len(hits); i++ {
+		require.GreaterOrEqual(t, hits[i-1].Score, hits[i].Score)
+	}
+
+	require.Empty(t, idx.Search("the and of"))
+	require.Empty(t, idx.Search("lager"))
+}
@@ -0,0 +1,37 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are dropped by Tokenize; they match nearly every document.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "has": true,
+	"have": true, "in": true, "into": true, "is": true, "it": true, "its": true,
+	"of": true, "on": true, "or": true, "so": true, "that": true, "the": true,
+	"their": true, "this": true, "to": true, "was": true, "were": true,
+	"will": true, "with": true, "you": true, "your": true,
+}
+
+// Words splits text into lowercase words on anything that is not a letter
+// or a digit.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Tokenize returns the stemmed words of text without stop words.
+func Tokenize(text string) []string {
+	words := Words(text)
+	out := words[:0]
+	for _, w := range words {
+		if stopWords[w] {
+			continue
+		}
+		out = append(out, Stem(w))
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"interview-go/config"
+	"interview-go/internal/cache"
+)
+
+// newCache builds the cache configured under cache.l1 and cache.l2.
+// The namespace keeps entries of different callers apart on shared backends.
+// When both levels are set the result is a LayeredCache, wrapped in a
+// CompressedCache when cache.compression is enabled. The result always
+// counts its hits and misses.
+func newCache(cfg *config.Configuration, namespace string) (cache.Cache, error) {
+	c, err := newLevels(cfg, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	compression := cfg.Cache.Compression
+	if compression.Algorithm != cache.CompressionNone {
+		if c, err = cache.NewCompressed(c, compression.Algorithm, compression.Threshold); err != nil {
+			return nil, err
+		}
+	}
+	return cache.NewCounting(c), nil
+}
+
+func newLevels(cfg *config.Configuration, namespace string) (cache.Cache, error) {
+	l1, err := newBackend(cfg.Cache.L1, cfg, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("cache l1: %w", err)
+	}
+	l2, err := newBackend(cfg.Cache.L2, cfg, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("cache l2: %w", err)
+	}
+
+	switch {
+	case cfg.Cache.L2 == cache.BackendNone:
+		return l1, nil
+	case cfg.Cache.L1 == cache.BackendNone:
+		return l2, nil
+	default:
+		return cache.NewLayered(l1, l2), nil
+	}
+}
+
+func newBackend(backend string, cfg *config.Configuration, namespace string) (cache.Cache, error) {
+	switch backend {
+	case cache.BackendMemory:
+		return cache.NewInMemory(cfg.Cache.TTL, cfg.Cache.ClearTicker), nil
+	case cache.BackendSharded:
+		return cache.NewSharded(cfg.Cache.TTL, cfg.Cache.ClearTicker, cfg.Cache.Shards), nil
+	case cache.BackendDisk:
+		return cache.NewDisk(cfg.Cache.Disk.Dir, namespace, cfg.Cache.TTL, cfg.Cache.ClearTicker)
+	case cache.BackendRedis:
+		return cache.NewRedis(cache.RedisOptions{
+			Addr:     cfg.Cache.Redis.Addr,
+			Password: cfg.Cache.Redis.Password,
+			DB:       cfg.Cache.Redis.DB,
+			Timeout:  cfg.Cache.Redis.Timeout,
+			PoolSize: cfg.Cache.Redis.PoolSize,
+			Prefix:   namespace + ":",
+			TTL:      cfg.Cache.TTL,
+		}), nil
+	case cache.BackendNone:
+		return cache.NewNoop(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", cache.ErrUnknownBackend, backend)
+	}
+}
@@ -9,4 +9,16 @@ import (
 func BeerRoutes(g *echo.Group, h handler.HTTPHandler) {
 	g.GET("/getAll", h.ListAllBeers)
 	g.GET("/getFiltered", h.FilteredBeers)
+	g.GET("/search", h.SearchBeers)
+	g.POST("/search", h.AdvancedSearch)
+	g.GET("/stats", h.Stats)
+	g.GET("/compare", h.CompareBeers)
+	g.GET("", h.BeersByIDs)
+	g.GET("/:id", h.GetBeer)
+	g.GET("/:id/similar", h.SimilarBeers)
+}
+
+func FoodRoutes(g *echo.Group, h handler.HTTPHandler) {
+	g.GET("", h.Foods)
+	g.GET("/:food/beers", h.FoodBeers)
 }
@@ -12,6 +12,7 @@ import (
 
 	backendbeer "interview-go/backend/client"
 	beerapi "interview-go/internal/beer"
+	"interview-go/internal/cache"
 )
 
 type Server struct {
@@ -23,7 +24,9 @@ func NewServer(cfg *config.Configuration) (*Server, error) {
 	s := &Server{
 		cfg: cfg,
 	}
-	s.newEchoServer()
+	if err := s.newEchoServer(); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
@@ -40,7 +43,7 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return s.Echo.Shutdown(ctx)
 }
 
-func (s *Server) newEchoServer() {
+func (s *Server) newEchoServer() error {
 	e := echo.New()
 	e.HideBanner = true
 
@@ -54,6 +57,13 @@ func (s *Server) newEchoServer() {
 			"error": http.StatusText(code),
 			"msg":   err.Error(),
 		}
+		var dp interface{ Details() any }
+		if errors.As(err, &dp) {
+			resp["details"] = dp.Details()
+			if he != nil {
+				resp["msg"] = he.Message
+			}
+		}
 		if !c.Response().Committed {
 			_ = c.JSON(code, resp)
 		}
@@ -75,10 +85,43 @@ func (s *Server) newEchoServer() {
 		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 	})
 
+	beerCache, err := newCache(s.cfg, "beers")
+	if err != nil {
+		return err
+	}
+
+	var responseCache cache.Cache
+	if s.cfg.Cache.EncodedResponses {
+		responseCache, err = newCache(s.cfg, "responses")
+		if err != nil {
+			return err
+		}
+	}
+
+	caches := map[string]cache.Cache{"beers": beerCache}
+	if responseCache != nil {
+		caches["responses"] = responseCache
+	}
+	// like /health it is public: the counters name no keys and hold no values
+	s.Echo.GET("/cache/stats", func(c echo.Context) error {
+		stats := make(map[string]cache.Stats, len(caches))
+		for name, ch := range caches {
+			if sr, ok := ch.(cache.StatsReporter); ok {
+				stats[name] = sr.Stats()
+			}
+		}
+		return c.JSON(http.StatusOK, stats)
+	})
+
 	client := backendbeer.NewFakeBeerClient(500)
-	service := beerapi.NewService(client, s.cfg)
-	handler := beerapi.NewHandler(service)
+	service := beerapi.NewService(client, beerCache, s.cfg)
+	handler := beerapi.NewHandler(service, responseCache, s.cfg)
 
 	beers := s.Echo.Group("/beer")
 	BeerRoutes(beers, handler)
+
+	foods := s.Echo.Group("/foods")
+	FoodRoutes(foods, handler)
+
+	return nil
 }